@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/srliao/gansim/internal/pkg/combat"
+)
+
+const debugHelp = `commands:
+  step [n]                  advance n frames (default 1) printing every event
+  next                      advance to the next frame with an event
+  continue                  run until a breakpoint hits or the run ends
+  break <condition>         add a breakpoint; conditions are
+                              frame <n>
+                              crit
+                              aura <element>       i.e. break aura frozen
+                              reaction <reaction>  i.e. break reaction melt
+                              action [char] <action>
+                              buff <key>
+                              swap
+  breaks                    list breakpoints
+  delete <n>                remove breakpoint n
+  inject <char> <action> [key=value ...]
+                            run an action as soon as the character is free
+  state                     show the current state
+  help                      show this help
+  quit                      stop debugging`
+
+//debugMain is the debug subcommand; it steps through a run of the profile
+//interactively
+func debugMain(args []string) {
+	fs := flag.NewFlagSet("debug", flag.ExitOnError)
+	seconds := fs.Int("seconds", 120, "length of the run")
+	fs.Parse(args)
+
+	cfg, err := loadProfile("./current.yaml")
+	if err != nil {
+		log.Fatal(err)
+	}
+	//the debugger prints events itself
+	cfg.LogLevel = "warn"
+	actions, err := cfg.Actions()
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := combat.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d := combat.NewDebugger(s, actions)
+	(&debugger{d: d, out: os.Stdout, end: *seconds * 60}).run(os.Stdin)
+}
+
+type breakpoint struct {
+	desc  string
+	frame int //breaks at the start of this frame if > 0
+	event func(e combat.Event) bool
+}
+
+type debugger struct {
+	d      *combat.Debugger
+	out    io.Writer
+	end    int //last frame of the run
+	breaks []breakpoint
+	last   []combat.Event //events of the last frame stepped
+}
+
+func (g *debugger) run(in io.Reader) {
+	fmt.Fprintln(g.out, "type help for a list of commands")
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprintf(g.out, "[%v] > ", combat.PrintFrames(g.d.State().Frame))
+		if !sc.Scan() {
+			fmt.Fprintln(g.out)
+			return
+		}
+		f := strings.Fields(sc.Text())
+		if len(f) == 0 {
+			continue
+		}
+		if f[0] == "quit" || f[0] == "q" {
+			return
+		}
+		if err := g.exec(f[0], f[1:]); err != nil {
+			fmt.Fprintln(g.out, err)
+		}
+	}
+}
+
+func (g *debugger) exec(cmd string, args []string) error {
+	switch cmd {
+	case "step", "s":
+		n := 1
+		if len(args) > 0 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+				return fmt.Errorf("invalid number of frames: %v", args[0])
+			}
+		}
+		for i := 0; i < n && g.step(); i++ {
+		}
+	case "next", "n":
+		for g.step() {
+			if len(g.last) > 0 {
+				break
+			}
+		}
+	case "continue", "c":
+		for g.step() {
+			if b := g.hit(); b != "" {
+				fmt.Fprintf(g.out, "breakpoint: %v\n", b)
+				break
+			}
+		}
+	case "break", "b":
+		b, err := parseBreakpoint(args)
+		if err != nil {
+			return err
+		}
+		g.breaks = append(g.breaks, b)
+		fmt.Fprintf(g.out, "breakpoint %v: %v\n", len(g.breaks), b.desc)
+	case "breaks":
+		for i, b := range g.breaks {
+			fmt.Fprintf(g.out, "%v: %v\n", i+1, b.desc)
+		}
+	case "delete":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: delete <n>")
+		}
+		i, err := strconv.Atoi(args[0])
+		if err != nil || i < 1 || i > len(g.breaks) {
+			return fmt.Errorf("no breakpoint %v", args[0])
+		}
+		g.breaks = append(g.breaks[:i-1], g.breaks[i:]...)
+	case "inject", "i":
+		return g.inject(args)
+	case "state", "p":
+		printState(g.out, g.d.State())
+	case "help", "h":
+		fmt.Fprintln(g.out, debugHelp)
+	default:
+		return fmt.Errorf("unknown command %v; type help for a list of commands", cmd)
+	}
+	return nil
+}
+
+//step advances one frame, printing its events; false once the run is over
+func (g *debugger) step() bool {
+	if g.d.State().Frame >= g.end {
+		fmt.Fprintln(g.out, "end of run")
+		g.last = nil
+		return false
+	}
+	g.last = g.d.Step()
+	for _, e := range g.last {
+		fmt.Fprintln(g.out, formatEvent(e))
+	}
+	return true
+}
+
+//hit returns the description of the first breakpoint triggered by the last
+//frame stepped, if any
+func (g *debugger) hit() string {
+	frame := g.d.State().Frame
+	for _, b := range g.breaks {
+		if b.frame > 0 && b.frame == frame {
+			return b.desc
+		}
+		if b.event == nil {
+			continue
+		}
+		for _, e := range g.last {
+			if b.event(e) {
+				return b.desc
+			}
+		}
+	}
+	return ""
+}
+
+func parseBreakpoint(args []string) (breakpoint, error) {
+	if len(args) == 0 {
+		return breakpoint{}, fmt.Errorf("usage: break <condition>; type help for conditions")
+	}
+	for i := range args[1:] {
+		args[i+1] = strings.ToLower(args[i+1])
+	}
+	b := breakpoint{desc: strings.Join(args, " ")}
+	switch {
+	case args[0] == "frame" && len(args) == 2:
+		f, err := strconv.Atoi(args[1])
+		if err != nil || f < 1 {
+			return b, fmt.Errorf("invalid frame: %v", args[1])
+		}
+		b.frame = f
+	case args[0] == "crit" && len(args) == 1:
+		b.event = func(e combat.Event) bool {
+			return e.Type == combat.EventDamage && e.Crit
+		}
+	case args[0] == "aura" && len(args) == 2:
+		b.event = func(e combat.Event) bool {
+			return e.Type == combat.EventAuraApplied && string(e.Element) == args[1]
+		}
+	case args[0] == "reaction" && len(args) == 2:
+		b.event = func(e combat.Event) bool {
+			return e.Type == combat.EventReaction && string(e.Reaction) == args[1]
+		}
+	case args[0] == "action" && len(args) == 2:
+		b.event = func(e combat.Event) bool {
+			return e.Type == combat.EventActionStart && string(e.Action) == args[1]
+		}
+	case args[0] == "action" && len(args) == 3:
+		b.event = func(e combat.Event) bool {
+			return e.Type == combat.EventActionStart && strings.ToLower(e.Char) == args[1] && string(e.Action) == args[2]
+		}
+	case args[0] == "buff" && len(args) == 2:
+		b.event = func(e combat.Event) bool {
+			return e.Type == combat.EventBuffApplied && strings.ToLower(e.Key) == args[1]
+		}
+	case args[0] == "swap" && len(args) == 1:
+		b.event = func(e combat.Event) bool {
+			return e.Type == combat.EventSwap
+		}
+	default:
+		return b, fmt.Errorf("invalid condition: %v; type help for conditions", b.desc)
+	}
+	return b, nil
+}
+
+func (g *debugger) inject(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: inject <char> <action> [key=value ...]")
+	}
+	a := combat.Action{TargetCharIndex: -1, Type: combat.ActionType(strings.ToLower(args[1]))}
+	for i, c := range g.d.State().Characters {
+		if strings.EqualFold(c.Name, args[0]) {
+			a.TargetCharIndex = i
+			break
+		}
+	}
+	if a.TargetCharIndex < 0 {
+		return fmt.Errorf("no character named %v", args[0])
+	}
+	for _, kv := range args[2:] {
+		p := strings.SplitN(kv, "=", 2)
+		if len(p) != 2 {
+			return fmt.Errorf("invalid parameter %v; expected key=value", kv)
+		}
+		if a.Params == nil {
+			a.Params = make(combat.ActionParams)
+		}
+		a.Params[p[0]] = parseValue(p[1])
+	}
+	if err := g.d.Inject(a); err != nil {
+		return err
+	}
+	fmt.Fprintf(g.out, "queued %v %v\n", args[0], a.Type)
+	return nil
+}
+
+//parseValue turns a parameter into a number or bool where possible
+func parseValue(v string) interface{} {
+	if n, err := strconv.Atoi(v); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(v); err == nil {
+		return b
+	}
+	return v
+}
+
+func formatEvent(e combat.Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%v] %v", combat.PrintFrames(e.Frame), e.Type)
+	add := func(v interface{}) {
+		fmt.Fprintf(&b, " %v", v)
+	}
+	switch e.Type {
+	case combat.EventActionStart:
+		add(e.Char)
+		add(e.Action)
+		if len(e.Params) > 0 {
+			add(e.Params)
+		}
+	case combat.EventActionEnd:
+		add(e.Char)
+		add(e.Action)
+		fmt.Fprintf(&b, " (%v frames)", e.Frames)
+	case combat.EventSwap:
+		fmt.Fprintf(&b, " %v -> %v", e.From, e.Char)
+	case combat.EventDamage:
+		fmt.Fprintf(&b, " %v: %v (%v)", e.Char, e.Abil, e.Element)
+		if e.Blocked {
+			add("blocked by shield")
+			break
+		}
+		fmt.Fprintf(&b, " %.0f", e.Damage)
+		if e.Crit {
+			add("crit")
+		}
+	case combat.EventAuraApplied, combat.EventAuraRefreshed, combat.EventAuraExpired:
+		add(e.Element)
+	case combat.EventReaction:
+		fmt.Fprintf(&b, " %v: %v %v on the enemy", e.Char, e.Reaction, e.Element)
+	case combat.EventBuffApplied, combat.EventBuffExpired:
+		add(e.Source)
+		if e.Char != "" {
+			add(e.Char)
+		}
+		add(e.Key)
+	case combat.EventEnergy:
+		fmt.Fprintf(&b, " %v %+.1f", e.Char, e.Amount)
+		if e.Energy != nil {
+			fmt.Fprintf(&b, " (%.1f)", *e.Energy)
+		}
+	}
+	return b.String()
+}
+
+func printState(w io.Writer, st combat.State) {
+	fmt.Fprintf(w, "frame %v, active %v", st.Frame, st.Active)
+	if st.Current != nil {
+		fmt.Fprintf(w, ", %v %v for %v more frames", st.Current.Char, st.Current.Action, st.Cooldown)
+	}
+	fmt.Fprintln(w)
+	for _, c := range st.Characters {
+		fmt.Fprintf(w, "%v: hp %.0f/%.0f, energy %.1f/%v", c.Name, c.HP, c.MaxHP, c.Energy, c.MaxEnergy)
+		if c.Infusion != "" {
+			fmt.Fprintf(w, ", %v infusion", c.Infusion)
+		}
+		fmt.Fprintln(w)
+		for _, k := range sortedKeys(c.Cooldowns) {
+			fmt.Fprintf(w, "\tcooldown %v: %v\n", k, c.Cooldowns[k])
+		}
+		for _, k := range sortedKeys(c.Mods) {
+			fmt.Fprintf(w, "\tmod %v: %v\n", k, c.Mods[k])
+		}
+	}
+	fmt.Fprintf(w, "enemy: %.0f damage taken\n", st.Enemy.Damage)
+	if sh := st.Enemy.Shield; sh != nil {
+		fmt.Fprintf(w, "\t%v shield: %.2f gauge, %.0f hp\n", sh.Element, sh.Gauge, sh.HP)
+	}
+	auras := make(map[string]combat.AuraState)
+	for e, a := range st.Enemy.Auras {
+		auras[string(e)] = a
+	}
+	for _, e := range sortedKeys(auras) {
+		a := auras[e]
+		fmt.Fprintf(w, "\taura %v: %v%v, %v frames left\n", e, a.Gauge, a.Unit, a.Duration)
+	}
+	for _, k := range sortedKeys(st.Enemy.Statuses) {
+		fmt.Fprintf(w, "\tstatus %v: %v frames left\n", k, st.Enemy.Statuses[k])
+	}
+	for _, sh := range st.Shields {
+		fmt.Fprintf(w, "shield %v (%v): %.0f hp, %v frames left\n", sh.Key, sh.Element, sh.HP, sh.Duration)
+	}
+	for _, f := range st.Fields {
+		fmt.Fprintf(w, "field %v (%v): %v frames left\n", f.Key, f.Owner, f.Left)
+	}
+	for _, a := range st.Scheduled {
+		fmt.Fprintf(w, "scheduled %v: ran %v times\n", a.Key, a.Tick)
+	}
+}
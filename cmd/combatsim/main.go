@@ -1,49 +1,345 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
+	"text/tabwriter"
 	"time"
 
+	_ "github.com/srliao/gansim/internal/pkg/bennett"
 	"github.com/srliao/gansim/internal/pkg/combat"
 	_ "github.com/srliao/gansim/internal/pkg/ganyu"
+	_ "github.com/srliao/gansim/internal/pkg/xiao"
+	_ "github.com/srliao/gansim/internal/pkg/zhongli"
 	"gopkg.in/yaml.v2"
 )
 
-func main() {
-	var source []byte
-	var cfg combat.Profile
-	var err error
+const usage = `usage: combatsim [flags] [profile.yaml ...]
+       combatsim debug [flags]
+       combatsim optimize [flags]
+       combatsim serve [flags]
 
-	p := "./current.yaml"
+Runs the rotation of each profile and prints the results; ./current.yaml is
+used if no profile is given. Several profiles are compared in a table.
 
-	source, err = ioutil.ReadFile(p)
-	if err != nil {
+flags:`
+
+//options are the flags of a plain run
+type options struct {
+	end        combat.EndCondition
+	iterations int
+	seed       int64
+	logLevel   string
+	format     string
+
+	events   string
+	report   string
+	timeline int
+	record   string
+	replay   string
+	original bool
+}
+
+//profileRun is the outcome of running one profile
+type profileRun struct {
+	Path           string `json:",omitempty"`
+	Label          string
+	Seed           int64 //seed of the first iteration
+	Summary        combat.Summary
+	CycleDPS       float64             //mean over iterations
+	DamagePerCycle float64             //mean over iterations
+	Result         combat.Result       //of the first iteration
+	Divergences    []combat.Divergence `json:",omitempty"`
+	Elapsed        time.Duration
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "debug" {
+		debugMain(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "optimize" {
+		optimizeMain(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		serveMain(os.Args[2:])
+		return
+	}
+	var o options
+	flag.Float64Var(&o.end.Seconds, "seconds", 0, "length of each run in seconds; may be fractional. Runs are 90 seconds long if no time or cycle limit is given")
+	flag.IntVar(&o.end.Frames, "frames", 0, "length of each run in frames")
+	flag.IntVar(&o.end.Cycles, "cycles", 0, "stop each run after this many passes through the rotation")
+	flag.BoolVar(&o.end.EnemyDead, "until-dead", false, "stop each run once the enemy's hp is gone, or at the time or cycle limit if that comes first")
+	flag.IntVar(&o.iterations, "iterations", 1, "runs per profile")
+	flag.Int64Var(&o.seed, "seed", 0, "seed of the first run, later runs count up from it; 0 uses the profile's seed")
+	flag.StringVar(&o.logLevel, "log", "", "log level (debug, info, warn or error); overrides the profile's")
+	flag.StringVar(&o.format, "format", "text", "output format: text or json")
+	flag.StringVar(&o.events, "events", "", "write the event log of the first run to this file as newline delimited json")
+	flag.StringVar(&o.report, "report", "", "write an html report of the first run to this file")
+	flag.IntVar(&o.timeline, "timeline", 120, "seconds of the run shown in the report timeline")
+	flag.StringVar(&o.record, "record", "", "save the executed actions and seed of the first run to this replay file")
+	flag.StringVar(&o.replay, "replay", "", "re-execute the actions in this replay file instead of the rotation")
+	flag.BoolVar(&o.original, "original", false, "replay against the build saved in the replay file instead of the profile")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"./current.yaml"}
+	}
+	if err := o.validate(len(paths)); err != nil {
 		log.Fatal(err)
 	}
-	err = yaml.Unmarshal(source, &cfg)
+
+	var runs []profileRun
+	for _, p := range paths {
+		r, err := o.run(p)
+		if err != nil {
+			log.Fatalf("%v: %v", p, err)
+		}
+		runs = append(runs, r)
+	}
+
+	if o.format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(runs); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(runs) == 1 {
+		printRun(runs[0])
+		return
+	}
+	printComparison(runs)
+}
+
+func (o *options) validate(profiles int) error {
+	if o.format != "text" && o.format != "json" {
+		return fmt.Errorf("invalid output format: %v", o.format)
+	}
+	if o.iterations < 1 {
+		return fmt.Errorf("iterations has to be at least 1")
+	}
+	if profiles > 1 && (o.events != "" || o.report != "" || o.record != "" || o.replay != "") {
+		return fmt.Errorf("-events, -report, -record and -replay only work with a single profile")
+	}
+	if o.end.Seconds == 0 && o.end.Frames == 0 && o.end.Cycles == 0 {
+		o.end.Seconds = 90
+	}
+	return nil
+}
+
+//run loads a profile and simulates it
+func (o *options) run(path string) (profileRun, error) {
+	cfg, err := loadProfile(path)
 	if err != nil {
-		log.Fatal(err)
+		return profileRun{Path: path}, err
+	}
+	var rep *combat.Replay
+	if o.replay != "" {
+		data, err := ioutil.ReadFile(o.replay)
+		if err != nil {
+			return profileRun{Path: path}, err
+		}
+		r, err := combat.LoadReplay(data)
+		if err != nil {
+			return profileRun{Path: path}, err
+		}
+		rep = &r
+		if o.original {
+			cfg = rep.Profile
+		}
 	}
-	cfg.LogLevel = "warn"
+	pr, err := o.simulate(context.Background(), cfg, rep, nil)
+	pr.Path = path
+	return pr, err
+}
 
-	s, err := combat.New(cfg)
+//simulate runs a profile for the requested number of iterations, or replays
+//rep once if given. hook, if set, is called with every sim before it runs
+func (o *options) simulate(ctx context.Context, cfg combat.Profile, rep *combat.Replay, hook func(i int, s *combat.Sim)) (profileRun, error) {
+	var pr profileRun
+	if o.logLevel != "" {
+		cfg.LogLevel = o.logLevel
+	}
+	if o.seed != 0 {
+		cfg.Seed = o.seed
+	}
+	pr.Label = cfg.Label
+	actions, err := cfg.Actions()
 	if err != nil {
-		log.Fatal(err)
+		return pr, err
 	}
-	var actions = []combat.Action{
-		// {
-		// 	TargetCharIndex: 0,
-		// 	Type:            ActionTypeBurst,
-		// },
-		{
-			TargetCharIndex: 0,
-			Type:            combat.ActionTypeChargedAttack,
-		},
+	if len(actions) == 0 && rep == nil {
+		return pr, fmt.Errorf("profile has no rotation")
 	}
+
+	//the event log only covers the first run
+	var events *bufio.Writer
+	if o.events != "" {
+		f, err := os.Create(o.events)
+		if err != nil {
+			return pr, err
+		}
+		defer f.Close()
+		events = bufio.NewWriter(f)
+		defer events.Flush()
+	}
+
 	start := time.Now()
-	seconds := 60000
-	dmg := s.Run(seconds, actions)
-	elapsed := time.Since(start)
-	log.Printf("Running profile %v, total damage dealt: %.2f over %v seconds. DPS = %.2f. Sim took %s\n", p, dmg, seconds, dmg/float64(seconds), elapsed)
+	var first *combat.Sim
+	each := func(i int, s *combat.Sim) {
+		if hook != nil {
+			hook(i, s)
+		}
+		if i > 0 {
+			return
+		}
+		first = s
+		if events != nil {
+			s.LogEvents(events)
+		}
+	}
+	var results []combat.Result
+	if rep != nil {
+		//a replay comes out the same every time so it only runs once
+		s, err := combat.New(cfg)
+		if err != nil {
+			return pr, err
+		}
+		each(0, s)
+		var r combat.Result
+		r, pr.Divergences = s.Replay(*rep)
+		results = append(results, r)
+	} else {
+		if results, err = combat.MonteCarloContext(ctx, cfg, o.end, actions, o.iterations, each); err != nil {
+			return pr, err
+		}
+	}
+	pr.Elapsed = time.Since(start)
+	pr.Result = results[0]
+	//a replay runs with the recorded seed
+	pr.Seed = first.Seed()
+	if err := o.write(first, pr.Result); err != nil {
+		return pr, err
+	}
+
+	var dps, cycleDPS, perCycle []float64
+	for _, r := range results {
+		dps = append(dps, r.DPS)
+		cycleDPS = append(cycleDPS, r.CycleDPS)
+		perCycle = append(perCycle, r.DamagePerCycle)
+	}
+	pr.Summary = combat.Summarize(dps)
+	pr.CycleDPS = combat.Summarize(cycleDPS).Mean
+	pr.DamagePerCycle = combat.Summarize(perCycle).Mean
+	return pr, nil
+}
+
+//write saves the recording and report of the first run if asked for
+func (o *options) write(s *combat.Sim, r combat.Result) error {
+	if o.record != "" {
+		data, err := yaml.Marshal(s.Recording())
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(o.record, data, 0644); err != nil {
+			return err
+		}
+	}
+	if o.report != "" {
+		f, err := os.Create(o.report)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := report(f, r, o.timeline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printRun(pr profileRun) {
+	r := pr.Result
+	fmt.Printf("profile %v (seed %v): %.2f damage over %.2f seconds, %.2f dps. Sim took %s\n", pr.Path, pr.Seed, r.Damage, float64(r.Frames)/combat.FramesPerSecond, r.DPS, pr.Elapsed)
+	if s := pr.Summary; s.Runs > 1 {
+		fmt.Printf("%v runs: mean %.2f dps, min %.2f, max %.2f, std dev %.2f\n", s.Runs, s.Mean, s.Min, s.Max, s.StdDev)
+	}
+	if len(r.Cycles) > 0 {
+		fmt.Printf("%v full cycles: %.2f damage per cycle, %.2f dps over full cycles\n", len(r.Cycles), r.DamagePerCycle, r.CycleDPS)
+	}
+	if len(pr.Divergences) > 0 {
+		fmt.Printf("%v divergences from the replay:\n", len(pr.Divergences))
+		for _, d := range pr.Divergences {
+			fmt.Printf("\t%v\n", d)
+		}
+	}
+	for _, name := range sortedKeys(r.Characters) {
+		c := r.Characters[name]
+		fmt.Printf("%v: %.2f damage (%.1f%%), %v hits, %v crits, avg hit %.2f\n", name, c.Damage, 100*c.Damage/r.Damage, c.Hits, c.Crits, c.AverageHit)
+		for _, abil := range sortedKeys(c.Abilities) {
+			a := c.Abilities[abil]
+			fmt.Printf("\t%v: %.2f damage, %v hits, %v crits, avg hit %.2f\n", abil, a.Damage, a.Hits, a.Crits, a.AverageHit)
+		}
+	}
+	for _, name := range sortedKeys(r.Uptime.Mods) {
+		mods := r.Uptime.Mods[name]
+		for _, k := range sortedKeys(mods) {
+			fmt.Printf("%v %v uptime: %.1f%%\n", name, k, 100*mods[k].Fraction)
+		}
+	}
+	auras := make(map[string]float64)
+	for e, u := range r.Uptime.Auras {
+		auras[string(e)] = u.Fraction
+	}
+	for _, e := range sortedKeys(auras) {
+		fmt.Printf("%v aura uptime: %.1f%%\n", e, 100*auras[e])
+	}
+}
+
+//printComparison lays the profiles out side by side, relative to the best
+func printComparison(runs []profileRun) {
+	best := 0.0
+	for _, pr := range runs {
+		if pr.Summary.Mean > best {
+			best = pr.Summary.Mean
+		}
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "profile\tlabel\truns\tdps\tmin\tmax\tstd dev\tcycle dps\tdamage/cycle\tvs best")
+	for _, pr := range runs {
+		s := pr.Summary
+		rel := 0.0
+		if best > 0 {
+			rel = 100 * (s.Mean/best - 1)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%+.1f%%\n", pr.Path, pr.Label, s.Runs, s.Mean, s.Min, s.Max, s.StdDev, pr.CycleDPS, pr.DamagePerCycle, rel)
+	}
+	w.Flush()
+}
+
+//loadProfile reads a profile from a yaml file
+func loadProfile(path string) (combat.Profile, error) {
+	var cfg combat.Profile
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(source, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
 }
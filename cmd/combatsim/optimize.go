@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/srliao/gansim/internal/pkg/combat"
+)
+
+//optimizeMain is the optimize subcommand; it searches for the rotations of the
+//profile's team that deal the most damage
+func optimizeMain(args []string) {
+	fs := flag.NewFlagSet("optimize", flag.ExitOnError)
+	seconds := fs.Int("seconds", 30, "length of the fight")
+	beam := fs.Int("beam", 8, "sequences kept after each step of the search")
+	top := fs.Int("top", 5, "number of rotations to show")
+	settle := fs.Int("settle", 600, "frames of pending damage counted when ranking partial sequences")
+	actions := fs.String("actions", "", "actions each character may use, i.e. \"Ganyu:charge,burst,skill;Bennett:skill,burst:hold=1\"; characters left out may use everything they implement")
+	bursts := fs.Bool("bursts", false, "only keep rotations where every character bursts at least once")
+	fs.Parse(args)
+
+	cfg, err := loadProfile("./current.yaml")
+	if err != nil {
+		log.Fatal(err)
+	}
+	//every branch of the search is a run of its own
+	cfg.LogLevel = "error"
+	allowed, err := parseAllowed(*actions)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r, err := combat.Search(cfg, combat.SearchOptions{
+		Frames:        *seconds * 60,
+		Actions:       allowed,
+		RequireBursts: *bursts,
+		Beam:          *beam,
+		Top:           *top,
+		Settle:        *settle,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i, c := range r {
+		fmt.Printf("%v. %.2f dps (%.0f damage)\n", i+1, c.DPS, c.Damage)
+		fmt.Printf("\t%v\n", formatSequence(cfg, c.Actions))
+	}
+}
+
+//parseAllowed reads the -actions flag: characters separated by ;, each a name
+//then : and a comma separated list of actions with optional key=value params
+//after further colons
+func parseAllowed(v string) (map[string][]combat.Action, error) {
+	if v == "" {
+		return nil, nil
+	}
+	r := make(map[string][]combat.Action)
+	for _, part := range strings.Split(v, ";") {
+		p := strings.SplitN(part, ":", 2)
+		if len(p) != 2 || strings.TrimSpace(p[0]) == "" {
+			return nil, fmt.Errorf("invalid actions %q; expected name:action,action", part)
+		}
+		name := strings.TrimSpace(p[0])
+		for _, s := range strings.Split(p[1], ",") {
+			f := strings.Split(strings.TrimSpace(s), ":")
+			a := combat.Action{Type: combat.ActionType(strings.ToLower(f[0]))}
+			for _, kv := range f[1:] {
+				x := strings.SplitN(kv, "=", 2)
+				if len(x) != 2 {
+					return nil, fmt.Errorf("invalid parameter %v; expected key=value", kv)
+				}
+				if a.Params == nil {
+					a.Params = make(combat.ActionParams)
+				}
+				a.Params[x[0]] = parseValue(x[1])
+			}
+			r[name] = append(r[name], a)
+		}
+	}
+	return r, nil
+}
+
+//formatSequence prints a rotation compactly, folding repeats of the same
+//action into a count
+func formatSequence(p combat.Profile, list []combat.Action) string {
+	var out []string
+	for i := 0; i < len(list); {
+		n := 1
+		for i+n < len(list) && sameAction(list[i], list[i+n]) {
+			n++
+		}
+		a := list[i]
+		s := fmt.Sprintf("%v %v", p.Characters[a.TargetCharIndex].Name, a.Type)
+		if len(a.Params) > 0 {
+			s += fmt.Sprintf(" %v", a.Params)
+		}
+		if n > 1 {
+			s += fmt.Sprintf(" x%v", n)
+		}
+		out = append(out, s)
+		i += n
+	}
+	return strings.Join(out, ", ")
+}
+
+func sameAction(a, b combat.Action) bool {
+	return a.TargetCharIndex == b.TargetCharIndex && a.Type == b.Type && fmt.Sprint(a.Params) == fmt.Sprint(b.Params)
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/srliao/gansim/internal/pkg/combat"
+)
+
+//rollingWindow is the number of seconds rolling dps is averaged over
+const rollingWindow = 10
+
+//report renders the result as an html page; the timeline only shows the first
+//timeline seconds of the run
+func report(w io.Writer, r combat.Result, timeline int) error {
+	page := components.NewPage()
+	page.PageTitle = "combat simulation report"
+	page.AddCharts(
+		dpsChart(r),
+		timelineChart(r, timeline),
+		pieChart("damage by character", characterShare(r)),
+		pieChart("damage by ability", abilityShare(r)),
+	)
+
+	var buf bytes.Buffer
+	if err := page.Render(&buf); err != nil {
+		return err
+	}
+	var table bytes.Buffer
+	if err := summaryTemplate.Execute(&table, summarize(r)); err != nil {
+		return err
+	}
+	//the summary goes at the top of the page
+	out := bytes.Replace(buf.Bytes(), []byte("<body>"), append([]byte("<body>\n"), table.Bytes()...), 1)
+	_, err := w.Write(out)
+	return err
+}
+
+func dpsChart(r combat.Result) *charts.Line {
+	var x []string
+	var cumulative, rolling []opts.LineData
+	total := 0.0
+	for i, d := range r.DamagePerSecond {
+		total += d
+		x = append(x, fmt.Sprint(i+1))
+		cumulative = append(cumulative, opts.LineData{Value: total / float64(i+1), Symbol: "none"})
+
+		start := i - rollingWindow + 1
+		if start < 0 {
+			start = 0
+		}
+		window := 0.0
+		for _, v := range r.DamagePerSecond[start : i+1] {
+			window += v
+		}
+		rolling = append(rolling, opts.LineData{Value: window / float64(i+1-start), Symbol: "none"})
+	}
+
+	c := charts.NewLine()
+	c.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Width: "1200px"}),
+		charts.WithTitleOpts(opts.Title{Title: "dps over time"}),
+		charts.WithXAxisOpts(opts.XAxis{Name: "seconds"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "dps"}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true, Trigger: "axis"}),
+		charts.WithLegendOpts(opts.Legend{Show: true, Right: "0%"}),
+		charts.WithDataZoomOpts(opts.DataZoom{Type: "slider"}),
+	)
+	c.SetXAxis(x).
+		AddSeries("cumulative", cumulative).
+		AddSeries(fmt.Sprintf("rolling (%vs)", rollingWindow), rolling)
+	return c
+}
+
+//timelineChart draws a gantt style chart; each row is an action, cooldown,
+//buff or aura and each bar an interval it was active
+func timelineChart(r combat.Result, timeline int) *charts.Line {
+	limit := timeline * 60
+	var rows []string
+	series := make(map[string][]opts.LineData)
+	var order []string
+	add := func(kind, row string, intervals []combat.Interval) {
+		if _, ok := series[kind]; !ok {
+			order = append(order, kind)
+		}
+		rows = append(rows, row)
+		data := series[kind]
+		for _, v := range intervals {
+			if v.Start >= limit {
+				break
+			}
+			end := v.End
+			if end > limit {
+				end = limit
+			}
+			data = append(data,
+				opts.LineData{Value: []interface{}{float64(v.Start) / 60, row}, Symbol: "none"},
+				opts.LineData{Value: []interface{}{float64(end) / 60, row}, Symbol: "none"},
+				opts.LineData{Value: "-"},
+			)
+		}
+		series[kind] = data
+	}
+
+	for _, p := range r.Profile.Characters {
+		name := p.Name
+		actions := make(map[combat.ActionType][]combat.Interval)
+		for _, a := range r.Actions {
+			if a.Char == name {
+				actions[a.Action] = append(actions[a.Action], a.Interval)
+			}
+		}
+		for _, t := range sortedActions(actions) {
+			add("actions", fmt.Sprintf("%v %v", name, t), actions[t])
+		}
+		for _, k := range sortedKeys(r.Uptime.Cooldowns[name]) {
+			add("cooldowns", fmt.Sprintf("%v %v", name, k), r.Uptime.Cooldowns[name][k].Intervals)
+		}
+		for _, k := range sortedKeys(r.Uptime.Mods[name]) {
+			add("buffs", fmt.Sprintf("%v %v", name, k), r.Uptime.Mods[name][k].Intervals)
+		}
+	}
+	for _, k := range sortedKeys(r.Uptime.Fields) {
+		add("fields", "field "+k, r.Uptime.Fields[k].Intervals)
+	}
+	for _, k := range sortedKeys(r.Uptime.Statuses) {
+		add("enemy status", "status "+k, r.Uptime.Statuses[k].Intervals)
+	}
+	auras := make(map[string]*combat.Uptime)
+	for e, u := range r.Uptime.Auras {
+		auras[fmt.Sprint(e)] = u
+	}
+	for _, k := range sortedKeys(auras) {
+		add("enemy auras", "aura "+k, auras[k].Intervals)
+	}
+
+	c := charts.NewLine()
+	c.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Width: "1200px", Height: fmt.Sprintf("%vpx", 100+25*len(rows))}),
+		charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("timeline (first %vs)", timeline)}),
+		charts.WithXAxisOpts(opts.XAxis{Name: "seconds", Type: "value"}),
+		charts.WithYAxisOpts(opts.YAxis{Type: "category", Data: rows}),
+		charts.WithLegendOpts(opts.Legend{Show: true, Right: "0%"}),
+		charts.WithDataZoomOpts(opts.DataZoom{Type: "slider"}),
+	)
+	for _, kind := range order {
+		c.AddSeries(kind, series[kind], charts.WithLineStyleOpts(opts.LineStyle{Width: 12}))
+	}
+	return c
+}
+
+func pieChart(title string, share map[string]float64) *charts.Pie {
+	var data []opts.PieData
+	for _, k := range sortedKeys(share) {
+		data = append(data, opts.PieData{Name: k, Value: share[k]})
+	}
+	c := charts.NewPie()
+	c.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Width: "1200px"}),
+		charts.WithTitleOpts(opts.Title{Title: title}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true}),
+	)
+	c.AddSeries(title, data, charts.WithLabelOpts(opts.Label{Show: true, Formatter: "{b}: {d}%"}))
+	return c
+}
+
+func characterShare(r combat.Result) map[string]float64 {
+	share := make(map[string]float64)
+	for name, c := range r.Characters {
+		share[name] = c.Damage
+	}
+	return share
+}
+
+func abilityShare(r combat.Result) map[string]float64 {
+	share := make(map[string]float64)
+	for name, c := range r.Characters {
+		for abil, a := range c.Abilities {
+			share[name+": "+abil] = a.Damage
+		}
+	}
+	return share
+}
+
+type summaryRow struct {
+	Name       string
+	Damage     string
+	Share      string
+	Hits       int
+	Crits      int
+	AverageHit string
+	Ability    bool //indented under its character
+}
+
+type summary struct {
+	Label       string
+	Seconds     string
+	Damage      string
+	DPS         string
+	DamageTaken string
+	Rows        []summaryRow
+}
+
+func summarize(r combat.Result) summary {
+	s := summary{
+		Label:       r.Profile.Label,
+		Seconds:     fmt.Sprintf("%.2f", float64(r.Frames)/60),
+		Damage:      fmt.Sprintf("%.0f", r.Damage),
+		DPS:         fmt.Sprintf("%.2f", r.DPS),
+		DamageTaken: fmt.Sprintf("%.0f", r.DamageTaken),
+	}
+	row := func(name string, d combat.DamageStats) summaryRow {
+		share := 0.0
+		if r.Damage > 0 {
+			share = 100 * d.Damage / r.Damage
+		}
+		return summaryRow{
+			Name:       name,
+			Damage:     fmt.Sprintf("%.0f", d.Damage),
+			Share:      fmt.Sprintf("%.1f%%", share),
+			Hits:       d.Hits,
+			Crits:      d.Crits,
+			AverageHit: fmt.Sprintf("%.0f", d.AverageHit),
+		}
+	}
+	for _, name := range sortedKeys(r.Characters) {
+		c := r.Characters[name]
+		s.Rows = append(s.Rows, row(name, c.DamageStats))
+		for _, abil := range sortedKeys(c.Abilities) {
+			a := row(abil, *c.Abilities[abil])
+			a.Ability = true
+			s.Rows = append(s.Rows, a)
+		}
+	}
+	return s
+}
+
+var summaryTemplate = template.Must(template.New("summary").Parse(`<div style="margin: 20px">
+<h2>{{.Label}}</h2>
+<p>{{.Seconds}}s, {{.Damage}} damage, {{.DPS}} dps, {{.DamageTaken}} damage taken</p>
+<table border="1" cellpadding="4" style="border-collapse: collapse">
+<tr><th>name</th><th>damage</th><th>share</th><th>hits</th><th>crits</th><th>avg hit</th></tr>
+{{range .Rows}}<tr><td{{if .Ability}} style="padding-left: 20px"{{end}}>{{.Name}}</td><td>{{.Damage}}</td><td>{{.Share}}</td><td>{{.Hits}}</td><td>{{.Crits}}</td><td>{{.AverageHit}}</td></tr>
+{{end}}</table>
+</div>
+`))
+
+//sortedKeys returns the keys of a map with string keys in order
+func sortedKeys(m interface{}) []string {
+	var r []string
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		r = append(r, k.String())
+	}
+	sort.Strings(r)
+	return r
+}
+
+func sortedActions(m map[combat.ActionType][]combat.Interval) []combat.ActionType {
+	var r []combat.ActionType
+	for k := range m {
+		r = append(r, k)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i] < r[j] })
+	return r
+}
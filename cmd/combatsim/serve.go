@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/srliao/gansim/internal/pkg/combat"
+)
+
+const serveHelp = `endpoints:
+  POST   /jobs              submit a job; the body is a json object with the
+                            Profile and optionally Seconds, Frames, Cycles,
+                            UntilDead, Iterations, Seed and Events (true to
+                            get the event log of the first run back)
+  GET    /jobs              list every job
+  GET    /jobs/<id>         state and progress of a job
+  GET    /jobs/<id>/result  result of a finished job
+  DELETE /jobs/<id>         cancel a job, or forget it once it's over
+
+jobs that are over are forgotten on their own once they've been kept for
+-keep`
+
+//serveMain is the serve subcommand; it runs sims submitted over a local json
+//api
+func serveMain(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	workers := fs.Int("workers", runtime.NumCPU(), "jobs run at the same time; the rest wait their turn")
+	level := fs.String("log", "warn", "log level of every sim")
+	keep := fs.Duration("keep", time.Hour, "how long jobs that are over are kept before they're forgotten")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: combatsim serve [flags]\n\nflags:")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), serveHelp)
+	}
+	fs.Parse(args)
+	if *workers < 1 {
+		log.Fatal("workers has to be at least 1")
+	}
+	if *keep <= 0 {
+		log.Fatal("keep has to be positive")
+	}
+
+	srv := newServer(*workers, *level, *keep)
+	log.Printf("listening on %v\n", *addr)
+	log.Fatal(http.ListenAndServe(*addr, srv))
+}
+
+//jobRequest is the body of a job submission
+type jobRequest struct {
+	Profile    combat.Profile
+	Seconds    float64 //run length; 90 seconds if no time or cycle limit is given
+	Frames     int
+	Cycles     int
+	UntilDead  bool
+	Iterations int   //defaults to 1
+	Seed       int64 //seed of the first run; 0 uses the profile's seed
+	Events     bool  //keep the event log of the first run
+}
+
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobDone      = "done"
+	jobFailed    = "failed"
+	jobCancelled = "cancelled"
+)
+
+//jobStatus is what's reported about a job while polling
+type jobStatus struct {
+	ID       string
+	State    string
+	Progress float64 //fraction of the job done, from 0 to 1
+	Error    string  `json:",omitempty"`
+	Created  time.Time
+}
+
+//jobResult is the result of a finished job
+type jobResult struct {
+	profileRun
+	Events []combat.Event `json:",omitempty"`
+}
+
+type job struct {
+	mu     sync.Mutex
+	status jobStatus
+	result *jobResult
+	cancel context.CancelFunc
+	ended  time.Time //when the job got over; zero until then
+}
+
+func (j *job) get() jobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+func (j *job) over() bool {
+	switch j.get().State {
+	case jobDone, jobFailed, jobCancelled:
+		return true
+	}
+	return false
+}
+
+type server struct {
+	mu    sync.Mutex
+	jobs  map[string]*job
+	count int
+
+	level string
+	slots chan struct{} //bounds the jobs running at once
+	keep  time.Duration //how long jobs that are over stick around
+}
+
+func newServer(workers int, level string, keep time.Duration) *server {
+	return &server{
+		jobs:  make(map[string]*job),
+		level: level,
+		slots: make(chan struct{}, workers),
+		keep:  keep,
+	}
+}
+
+func (srv *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv.prune(time.Now())
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "jobs" && r.Method == http.MethodPost:
+		srv.submit(w, r)
+	case path == "jobs" && r.Method == http.MethodGet:
+		srv.list(w)
+	case len(parts) == 2 && parts[0] == "jobs" && r.Method == http.MethodGet:
+		if j := srv.find(w, parts[1]); j != nil {
+			writeJSON(w, http.StatusOK, j.get())
+		}
+	case len(parts) == 2 && parts[0] == "jobs" && r.Method == http.MethodDelete:
+		srv.remove(w, parts[1])
+	case len(parts) == 3 && parts[0] == "jobs" && parts[2] == "result" && r.Method == http.MethodGet:
+		srv.result(w, parts[1])
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+func (srv *server) submit(w http.ResponseWriter, r *http.Request) {
+	var req jobRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid job: %v", err))
+		return
+	}
+	o := options{
+		end: combat.EndCondition{
+			Seconds:   req.Seconds,
+			Frames:    req.Frames,
+			Cycles:    req.Cycles,
+			EnemyDead: req.UntilDead,
+		},
+		iterations: req.Iterations,
+		seed:       req.Seed,
+		logLevel:   srv.level,
+		format:     "json",
+	}
+	if o.iterations == 0 {
+		o.iterations = 1
+	}
+	if err := o.validate(1); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	//catch a bad profile now rather than when the job gets to run
+	if _, err := req.Profile.Actions(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	p := req.Profile
+	p.LogLevel = srv.level
+	if _, err := combat.New(p); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	srv.mu.Lock()
+	srv.count++
+	j := &job{
+		status: jobStatus{ID: strconv.Itoa(srv.count), State: jobQueued, Created: time.Now()},
+		cancel: cancel,
+	}
+	srv.jobs[j.status.ID] = j
+	srv.mu.Unlock()
+
+	go srv.run(ctx, j, o, req)
+	w.Header().Set("Location", "/jobs/"+j.status.ID)
+	writeJSON(w, http.StatusAccepted, j.get())
+}
+
+//run waits for a free slot then runs the job
+func (srv *server) run(ctx context.Context, j *job, o options, req jobRequest) {
+	defer j.cancel()
+	select {
+	case srv.slots <- struct{}{}:
+		defer func() { <-srv.slots }()
+	case <-ctx.Done():
+		j.finish(nil, ctx.Err())
+		return
+	}
+	j.mu.Lock()
+	j.status.State = jobRunning
+	j.mu.Unlock()
+
+	var events []combat.Event
+	limit := o.end.FrameLimit()
+	hook := func(i int, s *combat.Sim) {
+		s.WatchEvents(func(e combat.Event) {
+			if i == 0 && req.Events {
+				events = append(events, e)
+			}
+			//runs without a time limit only count once they're over
+			p := float64(i)
+			if limit > 0 {
+				p += float64(e.Frame) / float64(limit)
+			}
+			j.mu.Lock()
+			j.status.Progress = p / float64(o.iterations)
+			j.mu.Unlock()
+		})
+	}
+	pr, err := o.simulate(ctx, req.Profile, nil, hook)
+	if err != nil {
+		j.finish(nil, err)
+		return
+	}
+	j.finish(&jobResult{profileRun: pr, Events: events}, nil)
+}
+
+func (j *job) finish(r *jobResult, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.ended = time.Now()
+	switch {
+	case errors.Is(err, context.Canceled):
+		j.status.State = jobCancelled
+	case err != nil:
+		j.status.State = jobFailed
+		j.status.Error = err.Error()
+	default:
+		j.status.State = jobDone
+		j.status.Progress = 1
+		j.result = r
+	}
+}
+
+//prune forgets the jobs that have been over for longer than srv.keep
+func (srv *server) prune(now time.Time) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	for id, j := range srv.jobs {
+		j.mu.Lock()
+		ended := j.ended
+		j.mu.Unlock()
+		if !ended.IsZero() && now.Sub(ended) > srv.keep {
+			delete(srv.jobs, id)
+		}
+	}
+}
+
+func (srv *server) list(w http.ResponseWriter) {
+	srv.mu.Lock()
+	var r []jobStatus
+	for _, j := range srv.jobs {
+		r = append(r, j.get())
+	}
+	srv.mu.Unlock()
+	sort.Slice(r, func(i, j int) bool { return r[i].Created.Before(r[j].Created) })
+	writeJSON(w, http.StatusOK, r)
+}
+
+//find looks up a job, writing a not found error if there's no such job
+func (srv *server) find(w http.ResponseWriter, id string) *job {
+	srv.mu.Lock()
+	j, ok := srv.jobs[id]
+	srv.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no job %v", id))
+		return nil
+	}
+	return j
+}
+
+func (srv *server) result(w http.ResponseWriter, id string) {
+	j := srv.find(w, id)
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	st, r := j.status, j.result
+	j.mu.Unlock()
+	switch st.State {
+	case jobDone:
+		writeJSON(w, http.StatusOK, r)
+	case jobFailed:
+		writeError(w, http.StatusUnprocessableEntity, errors.New(st.Error))
+	default:
+		writeError(w, http.StatusConflict, fmt.Errorf("job %v is %v", id, st.State))
+	}
+}
+
+//remove cancels a job that's still queued or running, and forgets one that
+//is over
+func (srv *server) remove(w http.ResponseWriter, id string) {
+	j := srv.find(w, id)
+	if j == nil {
+		return
+	}
+	if !j.over() {
+		j.cancel()
+		writeJSON(w, http.StatusAccepted, j.get())
+		return
+	}
+	srv.mu.Lock()
+	delete(srv.jobs, id)
+	srv.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, struct{ Error string }{err.Error()})
+}
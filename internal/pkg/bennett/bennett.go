@@ -0,0 +1,283 @@
+package bennett
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/srliao/gansim/internal/pkg/combat"
+	"go.uber.org/zap"
+)
+
+//go:embed frames.yaml
+var frames []byte
+
+var frameTable = combat.MustLoadFrameTable(frames)
+
+func init() {
+	combat.RegisterCharFunc("Bennett", New)
+}
+
+func New(s *combat.Sim, log *zap.SugaredLogger) *combat.Character {
+	c := &combat.Character{}
+	c.Attack = attack(c, log)
+	c.ChargeAttack = charge(c, log)
+	c.Skill = skill(c, log)
+	c.Burst = burst(c, log)
+	c.MaxEnergy = 60
+	c.Energy = 60
+	c.Element = combat.Pyro
+	c.Frames = frameTable
+	c.WeaponClass = combat.WeaponClassSword
+
+	return c
+}
+
+//delayed returns an action that applies the damage after the given number of frames
+func delayed(f func(s *combat.Sim) bool, delay int) combat.ActionFunc {
+	return func(s *combat.Sim, tick int) bool {
+		if tick < delay {
+			return false
+		}
+		f(s)
+		return true
+	}
+}
+
+//frames for each hit of the normal attack chain; [delay before hit, animation]
+var attackFrames = [][]int{
+	{12, 24},
+	{13, 27},
+	{25, 39},
+	{20, 48},
+	{30, 58},
+}
+
+func attack(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		//reset the chain if the last attack was too long ago
+		n, ok := c.Store["attack-counter"].(int)
+		if _, chain := c.Cooldown["attack-chain"]; !chain || !ok || n >= len(attackFrames) {
+			n = 0
+		}
+		mult := normalAttack[c.Profile.TalentLevel[combat.ActionTypeAttack]-1][n]
+		hit := n + 1
+		c.AddHit(func(s *combat.Sim) {
+			d := c.Snapshot(combat.Physical)
+			d.Abil = "Normal"
+			d.AbilType = combat.ActionTypeAttack
+			d.Mult = mult
+			damage := s.ApplyDamage(d)
+			log.Infof("[%v]: Bennett normal %v dealt %.0f damage", combat.PrintFrames(s.Frame), hit, damage)
+		}, attackFrames[n][0], fmt.Sprintf("%v-Bennett-Normal-%v", s.Frame, n))
+
+		c.Store["attack-counter"] = n + 1
+		c.Cooldown["attack-chain"] = attackFrames[n][1] + 30
+
+		return attackFrames[n][1]
+	}
+}
+
+func charge(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		for i, mult := range chargeAttack[c.Profile.TalentLevel[combat.ActionTypeAttack]-1] {
+			mult := mult
+			c.AddHit(func(s *combat.Sim) {
+				d := c.Snapshot(combat.Physical)
+				d.Abil = "Charge"
+				d.AbilType = combat.ActionTypeChargedAttack
+				d.Mult = mult
+				damage := s.ApplyDamage(d)
+				log.Infof("[%v]: Bennett charge attack dealt %.0f damage", combat.PrintFrames(s.Frame), damage)
+			}, 30+i*12, fmt.Sprintf("%v-Bennett-CA-%v", s.Frame, i))
+		}
+		delete(c.Cooldown, "attack-chain")
+
+		return 55
+	}
+}
+
+//inField returns whether bennett is the active character inside his own burst
+//field
+func inField(c *combat.Character, s *combat.Sim) bool {
+	return s.HasFieldEffect("Bennett-Burst") && s.Characters[s.Active] == c
+}
+
+//skillCD applies A2 (cd reduced by 20%) and A4 (cd halved inside the burst field)
+func skillCD(c *combat.Character, s *combat.Sim, cd int) int {
+	f := float64(cd) * 0.8
+	if inField(c, s) {
+		f = f * 0.5
+	}
+	return int(f)
+}
+
+//skillHits applies each of the skill's hits after the given delays
+func skillHits(c *combat.Character, s *combat.Sim, log *zap.SugaredLogger, abil string, mults []float64, delays []int) {
+	for i, mult := range mults {
+		mult := mult
+		c.AddHit(func(s *combat.Sim) {
+			d := c.Snapshot(combat.Pyro)
+			d.Abil = abil
+			d.AbilType = combat.ActionTypeSkill
+			d.Mult = mult
+			d.ApplyAura = true
+			d.AuraGauge = 1
+			d.AuraUnit = "A"
+			damage := s.ApplyDamage(d)
+			log.Infof("[%v]: Bennett %v dealt %.0f damage", combat.PrintFrames(s.Frame), abil, damage)
+		}, delays[i], fmt.Sprintf("%v-Bennett-Skill-%v", s.Frame, i))
+	}
+}
+
+//skill holds Passion Overload to charge level 1 while bennett is in his own
+//burst field, where A4 halves the longer cooldown of the hold, otherwise taps
+//it; param hold overrides this, hold: 0 to tap, true or 1 for charge level 1
+//and 2 for charge level 2
+func skill(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	hold1 := skillHold(c, log, 1)
+	hold2 := skillHold(c, log, 2)
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		n := 0
+		if inField(c, s) {
+			n = 1
+		}
+		if p.Has("hold") {
+			n = p.Int("hold", 0)
+		}
+		//the frame table is for the tap
+		if n > 0 {
+			c.Variant()
+		}
+		if n == 1 {
+			return hold1(s, p)
+		} else if n > 1 {
+			return hold2(s, p)
+		}
+		lvl := c.Profile.TalentLevel[combat.ActionTypeSkill] - 1
+		skillHits(c, s, log, "Passion Overload", []float64{press[lvl]}, []int{15})
+		c.Cooldown["skill-cd"] = skillCD(c, s, 5*60)
+		return 42
+	}
+}
+
+//skillHold holds Passion Overload to the given charge level (1 or 2)
+func skillHold(c *combat.Character, log *zap.SugaredLogger, level int) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		lvl := c.Profile.TalentLevel[combat.ActionTypeSkill] - 1
+		if level == 2 {
+			skillHits(c, s, log, "Passion Overload (Hold 2)", holdLvl2[lvl], []int{170, 185, 215})
+			c.Cooldown["skill-cd"] = skillCD(c, s, 10*60)
+			return 340
+		}
+		skillHits(c, s, log, "Passion Overload (Hold 1)", holdLvl1[lvl], []int{60, 75})
+		c.Cooldown["skill-cd"] = skillCD(c, s, int(7.5*60))
+		return 100
+	}
+}
+
+func burst(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		lvl := c.Profile.TalentLevel[combat.ActionTypeBurst] - 1
+
+		//field buffs snapshot bennett's stats at cast time
+		d := c.Snapshot(combat.Pyro)
+		d.Abil = "Fantastic Voyage"
+		d.AbilType = combat.ActionTypeBurst
+		d.Mult = burstDmg[lvl]
+		d.ApplyAura = true
+		d.AuraGauge = 2
+		d.AuraUnit = "B"
+
+		//atk bonus is based on bennett's base atk (character + weapon)
+		ratio := burstAtk[lvl]
+		if c.Profile.Constellation >= 1 {
+			ratio += 0.2
+		}
+		bonus := ratio * d.BaseAtk
+		heal := (burstHealPer[lvl]*d.MaxHP() + burstHealFlat[lvl]) * (1 + d.Stats[combat.Heal])
+
+		s.AddAction(delayed(func(s *combat.Sim) bool {
+			damage := s.ApplyDamage(d)
+			log.Infof("[%v]: Bennett burst dealt %.0f damage", combat.PrintFrames(s.Frame), damage)
+			return true
+		}, 33), fmt.Sprintf("%v-Bennett-Burst", s.Frame))
+
+		//the field lasts 12s; the atk buff goes to whoever is active inside it
+		//while they're above 70% hp, or regardless of hp with C1. whether it
+		//applies is worked out on the tick since max hp can't be looked up from
+		//inside a snapshot
+		atk := func(s *combat.Sim) bool {
+			x := s.Characters[s.Active]
+			return c.Profile.Constellation >= 1 || x.HP > 0.7*x.MaxHP()
+		}
+		c.Store["burst-atk"] = atk(s)
+		s.AddFieldEffect(combat.FieldEffect{
+			Key:      "Bennett-Burst",
+			Owner:    c.Profile.Name,
+			Duration: 12 * 60,
+			Target:   combat.FieldTargetActive,
+			Stats:    map[combat.StatType]float64{combat.ATK: bonus},
+			Cond: func(x *combat.Character) bool {
+				return c.Store["burst-atk"] == true
+			},
+		})
+		id := s.Frame
+		c.Store["burst-field"] = id
+		field := func(s *combat.Sim, tick int) bool {
+			if c.Store["burst-field"] != id {
+				//replaced by a newer field
+				return true
+			}
+			if !s.HasFieldEffect("Bennett-Burst") {
+				for _, x := range s.Characters {
+					removeC6(x)
+				}
+				return true
+			}
+			//C6: sword, claymore, and polearm characters gain pyro infusion and 15% pyro dmg
+			if c.Profile.Constellation >= 6 {
+				for i, x := range s.Characters {
+					if i != s.Active {
+						removeC6(x)
+						continue
+					}
+					switch x.WeaponClass {
+					case combat.WeaponClassSword, combat.WeaponClassClaymore, combat.WeaponClassPolearm:
+						x.Mods["Bennett-C6"] = map[combat.StatType]float64{combat.PyroP: 0.15}
+						if !x.HasInfusion("Bennett-C6") {
+							x.AddInfusion(combat.Infusion{
+								Key:     "Bennett-C6",
+								Element: combat.Pyro,
+								Source:  combat.InfusionExternal,
+								OnField: true,
+							})
+						}
+					}
+				}
+			}
+			c.Store["burst-atk"] = atk(s)
+			//heal the active character every second while at or below 70% hp
+			if tick%60 == 0 {
+				x := s.Characters[s.Active]
+				if x.HP <= 0.7*x.MaxHP() {
+					amt := s.HealCharacter(s.Active, heal)
+					log.Infof("[%v]: Bennett burst healed %v for %.0f", combat.PrintFrames(s.Frame), x.Profile.Name, amt)
+				}
+			}
+			return false
+		}
+		s.AddAction(field, fmt.Sprintf("%v-Bennett-Burst-Field", s.Frame))
+
+		c.Energy = 0
+		c.Cooldown["burst-cd"] = 15 * 60
+		return 51
+	}
+}
+
+func removeC6(c *combat.Character) {
+	if _, ok := c.Mods["Bennett-C6"]; !ok {
+		return
+	}
+	delete(c.Mods, "Bennett-C6")
+	c.RemoveInfusion("Bennett-C6")
+}
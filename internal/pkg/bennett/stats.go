@@ -0,0 +1,161 @@
+package bennett
+
+var (
+	normalAttack = [][]float64{
+		{0.4455, 0.4274, 0.5461, 0.5968, 0.719}, //lvl 1
+		{0.4818, 0.4622, 0.5906, 0.6454, 0.7775},
+		{0.518, 0.497, 0.635, 0.694, 0.8361},
+		{0.5698, 0.5467, 0.6985, 0.7634, 0.9197},
+		{0.6061, 0.5815, 0.743, 0.8119, 0.9782},
+		{0.6475, 0.6212, 0.7938, 0.8674, 1.0451},
+		{0.7045, 0.6759, 0.8636, 0.9438, 1.137},
+		{0.7615, 0.7306, 0.9334, 1.0201, 1.229},
+		{0.8185, 0.7852, 1.0033, 1.0964, 1.3209},
+		{0.8806, 0.8448, 1.0795, 1.1797, 1.4212},
+		{0.9519, 0.9132, 1.1668, 1.2751, 1.5362},
+		{1.0356, 0.9935, 1.2694, 1.3873, 1.6713},
+		{1.1193, 1.0738, 1.372, 1.4994, 1.8064},
+		{1.203, 1.1542, 1.4747, 1.6116, 1.9416},
+		{1.2944, 1.2418, 1.5867, 1.734, 2.0891}, //lvl 15
+	}
+	//charged attack hits twice
+	chargeAttack = [][]float64{
+		{0.559, 0.6072}, //lvl 1
+		{0.6045, 0.6566},
+		{0.65, 0.7061},
+		{0.715, 0.7767},
+		{0.7605, 0.8261},
+		{0.8125, 0.8826},
+		{0.884, 0.9602},
+		{0.9555, 1.0379},
+		{1.027, 1.1155},
+		{1.105, 1.2003},
+		{1.1944, 1.2973},
+		{1.2994, 1.4114},
+		{1.4044, 1.5255},
+		{1.5095, 1.6397},
+		{1.6242, 1.7642}, //lvl 15
+	}
+	press = []float64{
+		1.376, //lvl 1
+		1.4792,
+		1.5824,
+		1.72,
+		1.8232,
+		1.9264,
+		2.064,
+		2.2016,
+		2.3392,
+		2.4768,
+		2.6144,
+		2.752,
+		2.924,
+		3.096,
+		3.268, //lvl 15
+	}
+	//hold skill; charge level 1 hits twice, charge level 2 hits twice plus an explosion
+	holdLvl1 = [][]float64{
+		{0.84, 0.92}, //lvl 1
+		{0.903, 0.989},
+		{0.966, 1.058},
+		{1.05, 1.15},
+		{1.113, 1.219},
+		{1.176, 1.288},
+		{1.26, 1.38},
+		{1.344, 1.472},
+		{1.428, 1.564},
+		{1.512, 1.656},
+		{1.596, 1.748},
+		{1.68, 1.84},
+		{1.785, 1.955},
+		{1.89, 2.07},
+		{1.995, 2.185}, //lvl 15
+	}
+	holdLvl2 = [][]float64{
+		{0.88, 0.96, 1.32}, //lvl 1
+		{0.946, 1.032, 1.419},
+		{1.012, 1.104, 1.518},
+		{1.1, 1.2, 1.65},
+		{1.166, 1.272, 1.749},
+		{1.232, 1.344, 1.848},
+		{1.32, 1.44, 1.98},
+		{1.408, 1.536, 2.112},
+		{1.496, 1.632, 2.244},
+		{1.584, 1.728, 2.376},
+		{1.672, 1.824, 2.508},
+		{1.76, 1.92, 2.64},
+		{1.87, 2.04, 2.805},
+		{1.98, 2.16, 2.97},
+		{2.09, 2.28, 3.135}, //lvl 15
+	}
+	burstDmg = []float64{
+		2.328, //lvl 1
+		2.5026,
+		2.6772,
+		2.91,
+		3.0846,
+		3.2592,
+		3.492,
+		3.7248,
+		3.9576,
+		4.1904,
+		4.4232,
+		4.656,
+		4.947,
+		5.238,
+		5.529, //lvl 15
+	}
+	//burst field healing per second; % of max hp + flat amount
+	burstHealPer = []float64{
+		0.06, //lvl 1
+		0.0645,
+		0.069,
+		0.075,
+		0.0795,
+		0.084,
+		0.09,
+		0.096,
+		0.102,
+		0.108,
+		0.114,
+		0.12,
+		0.1275,
+		0.135,
+		0.1425, //lvl 15
+	}
+	burstHealFlat = []float64{
+		577, //lvl 1
+		635,
+		698,
+		765,
+		837,
+		914,
+		996,
+		1083,
+		1174,
+		1270,
+		1370,
+		1475,
+		1585,
+		1699,
+		1818, //lvl 15
+	}
+	//burst field atk bonus as a % of bennett's base atk
+	burstAtk = []float64{
+		0.56, //lvl 1
+		0.602,
+		0.644,
+		0.7,
+		0.742,
+		0.784,
+		0.84,
+		0.896,
+		0.952,
+		1.008,
+		1.064,
+		1.12,
+		1.19,
+		1.26,
+		1.33, //lvl 15
+	}
+)
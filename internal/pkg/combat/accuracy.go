@@ -0,0 +1,61 @@
+package combat
+
+import "math/rand"
+
+//Accuracy describes how well an ability lands
+type Accuracy struct {
+	WeakPoint float64 //chance to hit a weak point
+	Distance  float64 //distance to the target in meters
+	Miss      float64 //chance to miss the target entirely
+
+	rand *rand.Rand //the sim's random source; the global one if nil
+}
+
+//AccuracyProfile overrides a character's default accuracy for one ability;
+//fields left empty keep the character's default
+type AccuracyProfile struct {
+	WeakPoint *float64 `yaml:"WeakPoint"`
+	Distance  *float64 `yaml:"Distance"`
+	Miss      *float64 `yaml:"Miss"`
+}
+
+//Accuracy returns the accuracy of the given ability, applying any profile
+//overrides on top of the character's defaults
+func (c *Character) Accuracy(abil string, def Accuracy) Accuracy {
+	if c.sim != nil {
+		def.rand = c.sim.rand
+	}
+	p, ok := c.Profile.Accuracy[abil]
+	if !ok {
+		return def
+	}
+	if p.WeakPoint != nil {
+		def.WeakPoint = *p.WeakPoint
+	}
+	if p.Distance != nil {
+		def.Distance = *p.Distance
+	}
+	if p.Miss != nil {
+		def.Miss = *p.Miss
+	}
+	return def
+}
+
+//HitWeakPoint rolls for a weak point hit
+func (a Accuracy) HitWeakPoint() bool {
+	return randFloat(a.rand) < a.WeakPoint
+}
+
+//Missed rolls for a miss
+func (a Accuracy) Missed() bool {
+	return randFloat(a.rand) < a.Miss
+}
+
+//TravelFrames returns the number of frames a projectile moving at speed
+//(meters per frame) takes to reach the target
+func (a Accuracy) TravelFrames(speed float64) int {
+	if speed <= 0 {
+		return 0
+	}
+	return int(a.Distance / speed)
+}
@@ -0,0 +1,54 @@
+package combat
+
+import "testing"
+
+func TestAccuracyOverride(t *testing.T) {
+	wp := 0.5
+	c := &Character{}
+	c.Profile.Accuracy = map[string]AccuracyProfile{
+		"Arrow": {WeakPoint: &wp},
+	}
+	def := Accuracy{WeakPoint: 1, Distance: 5, Miss: 0.1}
+
+	a := c.Accuracy("Arrow", def)
+	if a.WeakPoint != 0.5 || a.Distance != 5 || a.Miss != 0.1 {
+		t.Errorf("expected only weak point overridden, got %+v", a)
+	}
+	if a := c.Accuracy("Bloom", def); a != def {
+		t.Errorf("expected defaults for ability without override, got %+v", a)
+	}
+	if f := a.TravelFrames(0.25); f != 20 {
+		t.Errorf("expected 20 travel frames, got %v", f)
+	}
+}
+
+func TestAmosTravel(t *testing.T) {
+	p := testProfile("Test Cryo")
+	p.Characters[0].WeaponName = "Amos' Bow"
+	p.Characters[0].WeaponRefinement = 1
+	s, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		abil   ActionType
+		travel int
+		bonus  float64
+	}{
+		{ActionTypeChargedAttack, 0, 0.12},
+		{ActionTypeChargedAttack, 13, 0.28},
+		{ActionTypeAttack, 60, 0.52},
+		{ActionTypeSkill, 60, 0},
+	}
+	for _, c := range cases {
+		d := s.Characters[0].Snapshot(Cryo)
+		d.AbilType = c.abil
+		d.TravelFrames = c.travel
+		for _, f := range s.effects[preDamageHook] {
+			f(&d)
+		}
+		if d.DmgBonus < c.bonus-0.0001 || d.DmgBonus > c.bonus+0.0001 {
+			t.Errorf("%v with %v travel frames: expected %v bonus got %v", c.abil, c.travel, c.bonus, d.DmgBonus)
+		}
+	}
+}
@@ -54,4 +54,8 @@ const (
 	GeoP     StatType = "Geo%"
 	EleP     StatType = "Ele%"
 	PhyP     StatType = "Phys%"
+	DmgP     StatType = "Dmg%" //all damage bonus
+
+	ShieldStrength StatType = "Shield%"
+	IncomingHeal   StatType = "IncomingHeal"
 )
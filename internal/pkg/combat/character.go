@@ -1,6 +1,7 @@
 package combat
 
 import (
+	"fmt"
 	"sync"
 
 	"go.uber.org/zap"
@@ -41,15 +42,15 @@ type Character struct {
 	//we can use store to keep track of the uptime on gouba/oz/pyronado/taunt etc..
 	//for something like baron bunny, if uptime = xx, then trigger damage
 	// tickHooks map[string]func(s *Sim) bool
-	//what about something like bennett ult or ganyu ult that affects char in the field?? this hook can only affect current actor?
+	//something like bennett ult or ganyu ult that affects char in the field is a FieldEffect instead
 
 	//ability functions to be defined by each character on how they will
 	//affect the unit
-	Attack       func(s *Sim) int
-	ChargeAttack func(s *Sim) int
-	PlungeAttack func(s *Sim) int
-	Skill        func(s *Sim) int
-	Burst        func(s *Sim) int
+	Attack       func(s *Sim, p ActionParams) int
+	ChargeAttack func(s *Sim, p ActionParams) int
+	PlungeAttack func(s *Sim, p ActionParams) int
+	Skill        func(s *Sim, p ActionParams) int
+	Burst        func(s *Sim, p ActionParams) int
 
 	//somehow we have to deal with artifact effects too?
 	ArtifactSetBonus func(e *Enemy)
@@ -59,17 +60,77 @@ type Character struct {
 	Mods  map[string]map[StatType]float64 //special effect mods (character only)
 
 	//character specific information; need this for damage calc
-	Profile   CharacterProfile
-	WeaponAtk float64
-	Talent    map[ActionType]int64 //talent levels
+	Profile     CharacterProfile
+	Element     eleType
+	WeaponAtk   float64
+	WeaponClass WeaponClass
+	Talent      map[ActionType]int64 //talent levels
 
 	//other stats
 	MaxEnergy  float64
 	MaxStamina float64
 	Energy     float64 //how much energy the character currently have
 	Stamina    float64 //how much stam the character currently have
+	HP         float64 //how much hp the character currently have
+
+	//infusions converting physical attacks, by key
+	infusions map[string]Infusion
+	//frames from each action into the next; actions not in the table use the
+	//frames returned by the ability
+	Frames FrameTable
+	//frames after the start of the action being executed that its last hit
+	//lands on, and whether it played another animation than the one in the
+	//frame table; reset before each action
+	hitmark int
+	variant bool
+	//keys of the hits the action being executed scheduled with AddHit
+	hits []string
+
+	sim *Sim
+}
+
+//Hitmark records that the action being executed lands a hit f frames after it
+//starts; a cancel can't end the action before its last hit
+func (c *Character) Hitmark(f int) {
+	if f > c.hitmark {
+		c.hitmark = f
+	}
+}
+
+//AddHit schedules f as a hit of the action being executed, landing delay
+//frames from now. The hit counts towards the action's Hitmark and is cancelled
+//if the action is interrupted before it lands
+func (c *Character) AddHit(f func(s *Sim), delay int, key string) {
+	c.Hitmark(delay)
+	c.hits = append(c.hits, key)
+	c.sim.AddAction(func(s *Sim, tick int) bool {
+		if tick < delay {
+			return false
+		}
+		f(s)
+		return true
+	}, key)
+}
+
+//Variant records that the action being executed played another animation than
+//the one in the frame table (i.e. a hold instead of a tap), so it plays out in
+//full
+func (c *Character) Variant() {
+	c.variant = true
 }
 
+//WeaponClass is the type of weapon a character wields
+type WeaponClass string
+
+//WeaponClass constants
+const (
+	WeaponClassSword    WeaponClass = "sword"
+	WeaponClassClaymore WeaponClass = "claymore"
+	WeaponClassPolearm  WeaponClass = "polearm"
+	WeaponClassBow      WeaponClass = "bow"
+	WeaponClassCatalyst WeaponClass = "catalyst"
+)
+
 //CharacterProfile ...
 type CharacterProfile struct {
 	Name                string               `yaml:"Name"`
@@ -87,6 +148,10 @@ type CharacterProfile struct {
 	WeaponBaseAtk       float64              `yaml:"WeaponBaseAtk"`
 	WeaponSecondaryStat map[StatType]float64 `yaml:"WeaponSecondaryStat"`
 	Artifacts           map[Slot]Artifact    `yaml:"Artifacts"`
+	//accuracy overrides keyed by ability name
+	Accuracy map[string]AccuracyProfile `yaml:"Accuracy"`
+	//frame table overrides, merged on top of the character's defaults
+	Frames FrameTable `yaml:"Frames"`
 }
 
 type ActionType string
@@ -115,32 +180,111 @@ func (c *Character) tick(s *Sim) {
 			c.Cooldown[k]--
 		}
 	}
+	c.tickInfusions()
+}
+
+//ability returns the function implementing the action, nil if the character
+//doesn't have one
+func (c *Character) ability(t ActionType) AbilFunc {
+	switch t {
+	case ActionTypeAttack:
+		return c.Attack
+	case ActionTypeChargedAttack:
+		return c.ChargeAttack
+	case ActionTypePlungeAttack:
+		return c.PlungeAttack
+	case ActionTypeSkill:
+		return c.Skill
+	case ActionTypeBurst:
+		return c.Burst
+	}
+	return nil
+}
+
+//Ready returns whether the character can use the action right now, and why
+//not if it can't
+func (c *Character) Ready(a ActionType) (bool, string) {
+	switch a {
+	case ActionTypeSkill:
+		if cd, ok := c.Cooldown["skill-cd"]; ok {
+			return false, fmt.Sprintf("skill on cooldown for %v more frames", cd)
+		}
+	case ActionTypeBurst:
+		if cd, ok := c.Cooldown["burst-cd"]; ok {
+			return false, fmt.Sprintf("burst on cooldown for %v more frames", cd)
+		}
+		if c.Energy < c.MaxEnergy {
+			return false, fmt.Sprintf("energy short: %.1f of %v", c.Energy, c.MaxEnergy)
+		}
+	}
+	return true, ""
+}
+
+//MaxHP returns the character's current max hp including any mods
+func (c *Character) MaxHP() float64 {
+	s := c.Snapshot(Physical)
+	return s.MaxHP()
 }
 
 func (c *Character) Snapshot(e eleType) snapshot {
 	var s snapshot
 	s.Stats = make(map[StatType]float64)
+	s.mods = make(map[StatType]float64)
+	s.field = make(map[StatType]float64)
 	for k, v := range c.Stats {
 		s.Stats[k] = v
 	}
 	//add char specific stat effect
 	for x, m := range c.Mods {
-		zap.S().Debugw("adding special char stat mod to snapshot", "key", x, "mods", m)
+		c.sim.log.Debugw("adding special char stat mod to snapshot", "key", x, "mods", m)
 		for k, v := range m {
 			s.Stats[k] += v
+			s.mods[k] += v
 		}
 	}
-	//add field effects
-
 	//other stats
 	s.CharName = c.Profile.Name
 	s.BaseAtk = c.Profile.BaseAtk + c.WeaponAtk
 	s.CharLvl = c.Profile.Level
 	s.BaseDef = c.Profile.BaseDef
+	s.BaseHP = c.Profile.BaseHP
 	s.Element = e
+	//infused attacks take the element's dmg bonus and apply its aura
+	if e == Physical {
+		if i := c.Infusion(); i != "" {
+			s.Element = i
+			s.infused = true
+			s.ApplyAura = true
+			s.AuraGauge = 1
+			s.AuraUnit = "A"
+		}
+	}
+	s.char = c
 
 	s.Stats[CR] += c.Profile.BaseCR
 	s.Stats[CD] += c.Profile.BaseCD
 
+	//add field effects
+	if c.sim != nil {
+		s.Frame = c.sim.Frame
+		before := make(map[StatType]float64)
+		for k, v := range s.Stats {
+			before[k] = v
+		}
+		bonus := s.bonus()
+		for k, f := range c.sim.effects[fieldEffectHook] {
+			if f(&s) {
+				c.sim.print(true, "effect (field) %v expired", k)
+				delete(c.sim.effects[fieldEffectHook], k)
+			}
+		}
+		for k, v := range s.Stats {
+			if d := v - before[k]; d != 0 {
+				s.field[k] = d
+			}
+		}
+		s.fieldBonus = s.bonus().sub(bonus)
+	}
+
 	return s
 }
@@ -0,0 +1,197 @@
+package combat
+
+import "errors"
+
+//Checkpoint is a copy of everything that changes while a sim runs: characters,
+//enemy, scheduled actions, effects, shields, fields, stats and the random
+//source. Scheduled actions and effects are closures over the sim they were
+//created in, so a checkpoint can only be restored into that same sim
+type Checkpoint struct {
+	sim *Sim
+
+	frame       int
+	active      int
+	cooldown    int
+	current     *Event
+	interrupt   int
+	damageTaken float64
+
+	chars  []Character
+	target Enemy
+
+	actions     map[string]task
+	actionOrder []string
+	effects     map[effectType]map[string]effectFunc
+	shields     map[string]Shield
+	fields      map[string]FieldEffect
+
+	stats     *Result
+	src       source
+	recording []ReplayAction
+}
+
+//Frame returns the frame the checkpoint was taken on
+func (cp *Checkpoint) Frame() int {
+	return cp.frame
+}
+
+//Checkpoint captures the current state of the sim. Values kept in a
+//character's Store are copied as is, so they should not be mutated in place
+func (s *Sim) Checkpoint() *Checkpoint {
+	cp := &Checkpoint{
+		sim:         s,
+		frame:       s.Frame,
+		active:      s.Active,
+		cooldown:    s.cooldown,
+		interrupt:   s.interrupt,
+		damageTaken: s.damageTaken,
+		stats:       s.stats.clone(),
+		src:         *s.src,
+		recording:   append([]ReplayAction(nil), s.recording...),
+	}
+	cp.save(s)
+	return cp
+}
+
+//Restore rolls the sim back to a checkpoint taken from it. The checkpoint is
+//left untouched and can be restored again. Anything already written to the
+//event log stays there; later events pick up from the restored state
+func (s *Sim) Restore(cp *Checkpoint) error {
+	if cp == nil || cp.sim != s {
+		return errors.New("checkpoint was not taken from this sim")
+	}
+	s.Frame = cp.frame
+	s.Active = cp.active
+	s.cooldown = cp.cooldown
+	s.interrupt = cp.interrupt
+	s.damageTaken = cp.damageTaken
+	s.stats = cp.stats.clone()
+	*s.src = cp.src
+	s.recording = append([]ReplayAction(nil), cp.recording...)
+	cp.load(s)
+	return nil
+}
+
+//save copies the sim's state that lives in maps and pointers
+func (cp *Checkpoint) save(s *Sim) {
+	if s.current != nil {
+		e := *s.current
+		cp.current = &e
+	}
+	for _, c := range s.Characters {
+		var x Character
+		copyCharacter(&x, c)
+		cp.chars = append(cp.chars, x)
+	}
+	copyEnemy(&cp.target, s.Target)
+	cp.actions, cp.actionOrder = copyActions(s.actions, s.actionOrder)
+	cp.effects = copyEffects(s.effects)
+	cp.shields = make(map[string]Shield, len(s.shields))
+	for k, v := range s.shields {
+		cp.shields[k] = v
+	}
+	cp.fields = make(map[string]FieldEffect, len(s.fields))
+	for k, v := range s.fields {
+		cp.fields[k] = v
+	}
+}
+
+//load copies the checkpoint's state back into the sim; characters and the
+//enemy are restored in place since closures hold pointers to them
+func (cp *Checkpoint) load(s *Sim) {
+	s.current = nil
+	if cp.current != nil {
+		e := *cp.current
+		s.current = &e
+	}
+	for i := range cp.chars {
+		copyCharacter(s.Characters[i], &cp.chars[i])
+	}
+	copyEnemy(s.Target, &cp.target)
+	s.actions, s.actionOrder = copyActions(cp.actions, cp.actionOrder)
+	s.effects = copyEffects(cp.effects)
+	s.shields = make(map[string]Shield, len(cp.shields))
+	for k, v := range cp.shields {
+		s.shields[k] = v
+	}
+	s.fields = make(map[string]FieldEffect, len(cp.fields))
+	for k, v := range cp.fields {
+		s.fields[k] = v
+	}
+}
+
+func copyCharacter(dst, src *Character) {
+	*dst = *src
+	dst.hits = append([]string(nil), src.hits...)
+	dst.Cooldown = make(map[string]int, len(src.Cooldown))
+	for k, v := range src.Cooldown {
+		dst.Cooldown[k] = v
+	}
+	dst.Store = make(map[string]interface{}, len(src.Store))
+	for k, v := range src.Store {
+		dst.Store[k] = v
+	}
+	dst.TickHooks = make(map[string]func(c *Character) bool, len(src.TickHooks))
+	for k, v := range src.TickHooks {
+		dst.TickHooks[k] = v
+	}
+	dst.Stats = copyStatMap(src.Stats)
+	dst.Mods = make(map[string]map[StatType]float64, len(src.Mods))
+	for k, v := range src.Mods {
+		dst.Mods[k] = copyStatMap(v)
+	}
+	dst.infusions = make(map[string]Infusion, len(src.infusions))
+	for k, v := range src.infusions {
+		dst.infusions[k] = v
+	}
+}
+
+func copyEnemy(dst, src *Enemy) {
+	*dst = *src
+	dst.ResMod = make(map[string]float64, len(src.ResMod))
+	for k, v := range src.ResMod {
+		dst.ResMod[k] = v
+	}
+	dst.auras = make(map[eleType]aura, len(src.auras))
+	for k, v := range src.auras {
+		dst.auras[k] = v
+	}
+	dst.status = make(map[string]int, len(src.status))
+	for k, v := range src.status {
+		dst.status[k] = v
+	}
+	if src.shield != nil {
+		sh := *src.shield
+		dst.shield = &sh
+	}
+}
+
+func copyActions(m map[string]task, order []string) (map[string]task, []string) {
+	c := make(map[string]task, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c, append([]string(nil), order...)
+}
+
+func copyEffects(m map[effectType]map[string]effectFunc) map[effectType]map[string]effectFunc {
+	c := make(map[effectType]map[string]effectFunc, len(m))
+	for hook, effects := range m {
+		c[hook] = make(map[string]effectFunc, len(effects))
+		for k, f := range effects {
+			c[hook][k] = f
+		}
+	}
+	return c
+}
+
+func copyStatMap(m map[StatType]float64) map[StatType]float64 {
+	if m == nil {
+		return nil
+	}
+	c := make(map[StatType]float64, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,150 @@
+package combat
+
+import (
+	"fmt"
+	"testing"
+)
+
+//checkpointSim sets up a character whose attack lands after a delay and whose
+//skill adds a mod for a while, so there's scheduled state to restore
+func checkpointSim(t *testing.T) *Sim {
+	p := testProfile("Test Pyro", "Test Cryo")
+	p.Seed = 7
+	p.Enemy.Shield = &EnemyShieldProfile{Element: Cryo, Gauge: 2, HP: 5000}
+	s, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range s.Characters {
+		c := c
+		c.Stats[CR] = 0.5
+		c.Stats[CD] = 1
+		c.Attack = func(s *Sim, p ActionParams) int {
+			s.AddAction(func(s *Sim, tick int) bool {
+				if tick < 20 {
+					return false
+				}
+				d := c.Snapshot(Pyro)
+				d.Abil = "Delayed"
+				d.Mult = 1
+				d.ApplyAura = true
+				d.AuraGauge = 1
+				d.AuraUnit = "A"
+				s.ApplyDamage(d)
+				return true
+			}, fmt.Sprintf("%v-attack", s.Frame))
+			return 40
+		}
+		c.Skill = func(s *Sim, p ActionParams) int {
+			c.Mods["skill"] = map[StatType]float64{ATKP: 0.5}
+			s.AddAction(func(s *Sim, tick int) bool {
+				if tick < 200 {
+					return false
+				}
+				delete(c.Mods, "skill")
+				return true
+			}, "skill-mod")
+			c.Cooldown["skill-cd"] = 300
+			return 30
+		}
+	}
+	return s
+}
+
+var checkpointRotation = []Action{
+	{TargetCharIndex: 0, Type: ActionTypeSkill},
+	{TargetCharIndex: 0, Type: ActionTypeAttack},
+	{TargetCharIndex: 0, Type: ActionTypeAttack},
+	{TargetCharIndex: 1, Type: ActionTypeAttack},
+	{TargetCharIndex: 1, Type: ActionTypeSkill},
+}
+
+func TestCheckpoint(t *testing.T) {
+	s := checkpointSim(t)
+	//stop mid action with hits, mods and cooldowns pending
+	first := s.Continue(613, checkpointRotation)
+	cp := s.Checkpoint()
+	if cp.Frame() != 613 {
+		t.Errorf("expected checkpoint on frame 613, got %v", cp.Frame())
+	}
+	a := s.Continue(900, checkpointRotation)
+	if first.Damage == a.Damage || first.Frames != 613 || len(first.Actions) >= len(a.Actions) {
+		t.Errorf("expected the earlier result to be unaffected by running on, got %v %v", first.Damage, first.Frames)
+	}
+
+	//an uninterrupted sim with the same seed matches
+	u := checkpointSim(t)
+	u.Continue(613, checkpointRotation)
+	want := u.Continue(900, checkpointRotation)
+	if want.Damage != a.Damage || want.Characters["Test Pyro"].Crits != a.Characters["Test Pyro"].Crits {
+		t.Fatalf("expected checkpointing not to change the run, got %v want %v", a.Damage, want.Damage)
+	}
+
+	//restoring replays the same continuation exactly, more than once
+	for i := 0; i < 2; i++ {
+		if err := s.Restore(cp); err != nil {
+			t.Fatal(err)
+		}
+		if s.Frame != 613 || s.Characters[0].Mods["skill"] == nil {
+			t.Errorf("expected frame 613 with the skill mod active, got %v %v", s.Frame, s.Characters[0].Mods)
+		}
+		b := s.Continue(900, checkpointRotation)
+		if b.Damage != a.Damage || len(b.Actions) != len(a.Actions) || b.Uptime.Mods["Test Pyro"]["skill"].Frames != a.Uptime.Mods["Test Pyro"]["skill"].Frames {
+			t.Errorf("restore %v: expected the same continuation, got %v want %v", i, b.Damage, a.Damage)
+		}
+		if b.Frames != 1513 {
+			t.Errorf("expected the run to end on frame 1513, got %v", b.Frames)
+		}
+	}
+
+	//a different continuation from the same point
+	s.Restore(cp)
+	c := s.Continue(900, []Action{{TargetCharIndex: 1, Type: ActionTypeAttack}})
+	if c.Damage == a.Damage {
+		t.Errorf("expected a different continuation to differ")
+	}
+
+	if err := u.Restore(cp); err == nil {
+		t.Errorf("expected restoring another sim's checkpoint to fail")
+	}
+}
+
+func TestCheckpointElectroResonance(t *testing.T) {
+	s, err := New(testProfile("Test Electro", "Test Electro 2", "Test Cryo", "Test Pyro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range s.Characters {
+		c.Energy = 0
+		c.MaxEnergy = 100
+	}
+	superconduct := func() float64 {
+		for _, i := range []int{2, 0} {
+			d := s.Characters[i].Snapshot(s.Characters[i].Element)
+			d.ApplyAura = true
+			d.AuraGauge = 1
+			d.AuraUnit = "A"
+			s.ApplyDamage(d)
+		}
+		return s.Characters[0].Energy
+	}
+
+	//the particle cooldown is restored along with everything else
+	cp := s.Checkpoint()
+	if e := superconduct(); e != 3 {
+		t.Fatalf("expected 3 energy from electro resonance, got %v", e)
+	}
+	onCD := s.Checkpoint()
+	if err := s.Restore(cp); err != nil {
+		t.Fatal(err)
+	}
+	if e := superconduct(); e != 3 {
+		t.Errorf("expected a particle again after restoring, got %v energy", e)
+	}
+	if err := s.Restore(onCD); err != nil {
+		t.Fatal(err)
+	}
+	if e := superconduct(); e != 3 {
+		t.Errorf("expected no particle while on cooldown, got %v energy", e)
+	}
+}
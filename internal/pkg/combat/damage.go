@@ -8,23 +8,44 @@ import (
 
 func (s *Sim) ApplyDamage(ds snapshot) float64 {
 
+	s.refreshSnapshot(&ds)
+
+	//infused attacks only apply their aura when off icd
+	if ds.infused && ds.ApplyAura && ds.char != nil {
+		ds.ApplyAura = ds.char.infusionICD()
+	}
+
 	ds.TargetLvl = s.Target.Level
 	ds.TargetRes = s.Target.Resist
+	for _, v := range s.Target.ResMod {
+		ds.ResMod += v
+	}
 
 	for k, f := range s.effects[preDamageHook] {
 		if f(&ds) {
-			print(s.Frame, true, "effect (pre damage) %v expired", k)
+			s.print(true, "effect (pre damage) %v expired", k)
 			delete(s.effects[preDamageHook], k)
 		}
 	}
 
-	print(s.Frame, true, "%v - %v triggered dmg", ds.CharName, ds.Abil)
+	s.print(true, "%v - %v triggered dmg", ds.CharName, ds.Abil)
+
+	//melt and vaporize amplify the hit that triggers them
+	if ds.ApplyAura {
+		ds.AmpMult = s.Target.amplifier(ds)
+	}
+
+	damage, crit := calcDmg(ds, s.damageMode, s.log)
 
-	damage := calcDmg(ds)
+	//an elemental shield takes the hit (and any gauge) instead of the enemy
+	if s.shieldHit(ds, damage) {
+		s.emit(Event{Type: EventDamage, Char: ds.CharName, Abil: ds.Abil, Element: ds.Element, Blocked: true, Detail: damageDetail(ds)})
+		return 0
+	}
 
 	for k, f := range s.effects[postDamageHook] {
 		if f(&ds) {
-			print(s.Frame, true, "effect (post damage) %v expired", k)
+			s.print(true, "effect (post damage) %v expired", k)
 			delete(s.effects[postDamageHook], k)
 		}
 	}
@@ -32,8 +53,32 @@ func (s *Sim) ApplyDamage(ds snapshot) float64 {
 	s.Target.damage += damage
 
 	//apply aura
+	//auras on the enemy before this hit, for the event log
+	var before map[eleType]bool
+	if ds.ApplyAura {
+		for k, f := range s.effects[preAuraAppHook] {
+			if f(&ds) {
+				s.print(true, "effect (pre aura app) %v expired", k)
+				delete(s.effects[preAuraAppHook], k)
+			}
+		}
+		before = s.Target.auraKeys()
+		ds.Reaction = s.Target.applyAura(ds)
+		if ds.Reaction != "" {
+			s.print(true, "%v - %v triggered %v", ds.CharName, ds.Abil, ds.Reaction)
+		}
+		for k, f := range s.effects[postAuraAppHook] {
+			if f(&ds) {
+				s.print(true, "effect (post aura app) %v expired", k)
+				delete(s.effects[postAuraAppHook], k)
+			}
+		}
+	}
+
+	s.stats.add(ds, damage, crit, s.Frame)
+	s.emit(Event{Type: EventDamage, Char: ds.CharName, Abil: ds.Abil, Element: ds.Element, Damage: damage, Crit: crit, Detail: damageDetail(ds)})
 	if ds.ApplyAura {
-		s.Target.applyAura(ds)
+		s.emitAuras(ds, before)
 	}
 
 	return damage
@@ -45,6 +90,7 @@ type snapshot struct {
 	AbilType ActionType //type of ability triggering the damage
 
 	HitWeakPoint bool
+	TravelFrames int //frames the projectile travelled before hitting, if any
 
 	TargetLvl int64
 	TargetRes float64
@@ -59,17 +105,65 @@ type snapshot struct {
 	FlatDmg      float64 //flat dmg; so far only zhongli
 	OtherMult    float64 //so far just for xingqiu C4
 
+	Reaction reactionType //reaction triggered by applying the aura, if any
+
+	//stat categories read live when the damage is applied; everything else is
+	//what the character had when the snapshot was taken. defaults to nothing
+	//(snapshot at cast)
+	Dynamic snapCategory
+	Frame   int //frame the snapshot was taken
+
+	char       *Character
+	infused    bool                 //infused physical attack; its aura is subject to the infusion icd
+	mods       map[StatType]float64 //stats from character mods when the snapshot was taken
+	field      map[StatType]float64 //stats from field effects when the snapshot was taken
+	fieldBonus fieldBonus           //other modifiers from field effects when the snapshot was taken
+
 	Stats      map[StatType]float64 //total character stats including from artifact, bonuses, etc...
 	BaseAtk    float64              //base attack used in calc
 	BaseDef    float64              //base def used in calc
+	BaseHP     float64              //base hp used in calc
 	DmgBonus   float64              //total damage bonus, including appropriate ele%, etc..
 	CharLvl    int64
 	DefMod     float64
 	ResMod     float64
 	ReactBonus float64 //reaction bonus %+ such as witch
+	AmpMult    float64 //melt/vaporize multiplier of the reaction the hit triggers; 0 if none
+}
+
+//MaxHP returns the total hp of the character at the time of the snapshot
+func (s *snapshot) MaxHP() float64 {
+	return s.BaseHP*(1+s.Stats[HPP]) + s.Stats[HP]
 }
 
-func calcDmg(d snapshot) float64 {
+//roll draws from the sim's random source, or the global one if the snapshot
+//isn't tied to a sim
+func (s *snapshot) roll() float64 {
+	var r *rand.Rand
+	if s.char != nil && s.char.sim != nil {
+		r = s.char.sim.rand
+	}
+	return randFloat(r)
+}
+
+//DamageMode determines how crits are handled when calculating damage
+type DamageMode string
+
+//DamageMode constants
+const (
+	//DamageModeRolled rolls for crit on every hit
+	DamageModeRolled DamageMode = "rolled"
+	//DamageModeAverage multiplies every hit by the expected crit bonus (1 + CR * CD)
+	DamageModeAverage DamageMode = "average"
+	//DamageModeNonCrit never crits
+	DamageModeNonCrit DamageMode = "noncrit"
+	//DamageModeCrit always crits
+	DamageModeCrit DamageMode = "crit"
+)
+
+//calcDmg returns the damage dealt by the snapshot and whether it crit; only
+//weak point hits count as crits in average mode
+func calcDmg(d snapshot, mode DamageMode, log *zap.SugaredLogger) (float64, bool) {
 
 	var st StatType
 	switch d.Element {
@@ -88,9 +182,9 @@ func calcDmg(d snapshot) float64 {
 	case Physical:
 		st = PhyP
 	}
-	d.DmgBonus += d.Stats[st]
+	d.DmgBonus += d.Stats[st] + d.Stats[DmgP]
 
-	zap.S().Debugw("calc", "base atk", d.BaseAtk, "flat +", d.Stats[ATK], "% +", d.Stats[ATKP], "bonus dmg", d.DmgBonus, "mul", d.Mult)
+	log.Debugw("calc", "base atk", d.BaseAtk, "flat +", d.Stats[ATK], "% +", d.Stats[ATKP], "bonus dmg", d.DmgBonus, "mul", d.Mult)
 	//calculate attack or def
 	var a float64
 	if d.UseDef {
@@ -102,7 +196,7 @@ func calcDmg(d snapshot) float64 {
 	base := d.Mult*a + d.FlatDmg
 	damage := base * (1 + d.DmgBonus)
 
-	zap.S().Debugw("calc", "total atk", a, "base dmg", base, "dmg + bonus", damage)
+	log.Debugw("calc", "total atk", a, "base dmg", base, "dmg + bonus", damage)
 
 	//make sure 0 <= cr <= 1
 	if d.Stats[CR] < 0 {
@@ -112,7 +206,7 @@ func calcDmg(d snapshot) float64 {
 		d.Stats[CR] = 1
 	}
 
-	zap.S().Debugw("calc", "cr", d.Stats[CR], "cd", d.Stats[CD], "def adj", d.DefMod, "res adj", d.ResMod, "char lvl", d.CharLvl, "target lvl", d.TargetLvl)
+	log.Debugw("calc", "cr", d.Stats[CR], "cd", d.Stats[CD], "def adj", d.DefMod, "res adj", d.ResMod, "char lvl", d.CharLvl, "target lvl", d.TargetLvl)
 
 	defmod := float64(d.CharLvl+100) / (float64(d.CharLvl+100) + float64(d.TargetLvl+100)*(1-d.DefMod))
 	//apply def mod
@@ -131,13 +225,32 @@ func calcDmg(d snapshot) float64 {
 	if d.OtherMult > 0 {
 		damage = damage * d.OtherMult
 	}
-	zap.S().Debugw("calc", "def mod", defmod, "res mod", resmod, "pre crit damage", damage)
+
+	//apply amplifying reaction, scaled by em
+	if d.AmpMult > 0 {
+		em := d.Stats[EM]
+		damage = damage * d.AmpMult * (1 + 2.78*em/(1400+em) + d.ReactBonus)
+	}
+	log.Debugw("calc", "def mod", defmod, "res mod", resmod, "pre crit damage", damage)
 
 	//check if crit
-	if rand.Float64() <= d.Stats[CR] || d.HitWeakPoint {
-		zap.S().Debugf("damage is crit!")
+	crit := false
+	switch mode {
+	case DamageModeAverage:
+		if d.HitWeakPoint {
+			return damage * (1 + d.Stats[CD]), true
+		}
+		return damage * (1 + d.Stats[CR]*d.Stats[CD]), false
+	case DamageModeNonCrit:
+	case DamageModeCrit:
+		crit = true
+	default:
+		crit = d.roll() <= d.Stats[CR] || d.HitWeakPoint
+	}
+	if crit {
+		log.Debugf("damage is crit!")
 		damage = damage * (1 + d.Stats[CD])
 	}
 
-	return damage
+	return damage, crit
 }
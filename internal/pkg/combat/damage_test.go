@@ -0,0 +1,75 @@
+package combat
+
+import (
+	"math"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestDamageMode(t *testing.T) {
+	d := snapshot{
+		Stats: map[StatType]float64{
+			CR:    0.6,
+			CD:    1.2,
+			ATKP:  0.5,
+			CryoP: 0.466,
+		},
+		Element:   Cryo,
+		BaseAtk:   1000,
+		Mult:      2,
+		CharLvl:   90,
+		TargetLvl: 90,
+		TargetRes: 0.1,
+	}
+	//1500 atk * 2 * 1.466 dmg bonus * 0.5 def * 0.9 res
+	base := 1500 * 2 * 1.466 * 0.5 * 0.9
+
+	cases := []struct {
+		mode     DamageMode
+		expected float64
+	}{
+		{DamageModeNonCrit, base},
+		{DamageModeCrit, base * 2.2},
+		{DamageModeAverage, base * (1 + 0.6*1.2)},
+	}
+	for _, c := range cases {
+		if dmg, _ := calcDmg(d, c.mode, zap.NewNop().Sugar()); math.Abs(dmg-c.expected) > 0.0001 {
+			t.Errorf("%v: expected %v got %v", c.mode, c.expected, dmg)
+		}
+	}
+
+	//weak point hits always crit
+	d.HitWeakPoint = true
+	if dmg, _ := calcDmg(d, DamageModeAverage, zap.NewNop().Sugar()); math.Abs(dmg-base*2.2) > 0.0001 {
+		t.Errorf("average weak point: expected %v got %v", base*2.2, dmg)
+	}
+	if dmg, _ := calcDmg(d, DamageModeRolled, zap.NewNop().Sugar()); math.Abs(dmg-base*2.2) > 0.0001 {
+		t.Errorf("rolled weak point: expected %v got %v", base*2.2, dmg)
+	}
+}
+
+func TestAmplifyingDamage(t *testing.T) {
+	d := snapshot{
+		Stats:     map[StatType]float64{EM: 200},
+		Element:   Pyro,
+		BaseAtk:   1000,
+		Mult:      1,
+		CharLvl:   90,
+		TargetLvl: 90,
+		AmpMult:   2,
+	}
+	//1000 atk * 0.5 def * 2 melt * (1 + 2.78 * 200 / 1600 em bonus)
+	expected := 1000 * 0.5 * 2 * (1 + 2.78*200/1600)
+	if dmg, _ := calcDmg(d, DamageModeNonCrit, zap.NewNop().Sugar()); math.Abs(dmg-expected) > 0.0001 {
+		t.Errorf("expected %v got %v", expected, dmg)
+	}
+}
+
+func TestInvalidDamageMode(t *testing.T) {
+	p := testProfile("Test Pyro")
+	p.DamageMode = "max"
+	if _, err := New(p); err == nil {
+		t.Errorf("expected error for invalid damage mode")
+	}
+}
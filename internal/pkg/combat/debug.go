@@ -0,0 +1,90 @@
+package combat
+
+import "fmt"
+
+//Debugger runs a sim one frame at a time
+type Debugger struct {
+	sim    *Sim
+	rot    *debugRotation
+	events []Event //events of the frame being stepped
+}
+
+//NewDebugger starts a run of s that repeats list, like Run, but only advances
+//when stepped
+func NewDebugger(s *Sim, list []Action) *Debugger {
+	d := &Debugger{
+		sim: s,
+		rot: &debugRotation{cycle: cycle{list: list}},
+	}
+	s.WatchEvents(func(e Event) {
+		d.events = append(d.events, e)
+	})
+	s.emit(Event{Type: EventSimStart, Version: EventVersion})
+	d.events = nil
+	return d
+}
+
+//Step simulates the current frame and returns the events it produced
+func (d *Debugger) Step() []Event {
+	d.events = nil
+	d.sim.step(d.rot)
+	d.sim.Frame++
+	return d.events
+}
+
+//Inject queues an action to run as soon as the character is free, ahead of
+//the rest of the rotation
+func (d *Debugger) Inject(a Action) error {
+	if a.TargetCharIndex < 0 || a.TargetCharIndex >= len(d.sim.Characters) {
+		return fmt.Errorf("invalid character index: %v", a.TargetCharIndex)
+	}
+	d.rot.queue = append(d.rot.queue, a)
+	return nil
+}
+
+//Injected returns the injected actions still waiting to run
+func (d *Debugger) Injected() []Action {
+	return append([]Action(nil), d.rot.queue...)
+}
+
+//State returns a summary of the sim on the frame about to be stepped
+func (d *Debugger) State() State {
+	return d.sim.State()
+}
+
+//Result returns the stats collected so far
+func (d *Debugger) Result() Result {
+	return d.sim.result()
+}
+
+//debugRotation runs injected actions first, then carries on with the list
+type debugRotation struct {
+	cycle
+	queue    []Action
+	injected bool //last action returned came from the queue
+}
+
+func (r *debugRotation) next(s *Sim) (Action, bool) {
+	if len(r.queue) > 0 {
+		r.injected = true
+		return r.queue[0], true
+	}
+	r.injected = false
+	return r.cycle.next(s)
+}
+
+func (r *debugRotation) advance() {
+	if r.injected {
+		r.queue = r.queue[1:]
+		r.injected = false
+		return
+	}
+	r.cycle.advance()
+}
+
+func (r *debugRotation) peek() (Action, bool) {
+	if len(r.queue) > 0 {
+		return r.queue[0], true
+	}
+	return r.cycle.peek()
+}
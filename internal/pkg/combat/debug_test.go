@@ -0,0 +1,68 @@
+package combat
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDebugger(t *testing.T) {
+	s := checkpointSim(t)
+	var log bytes.Buffer
+	s.LogEvents(&log)
+	d := NewDebugger(s, []Action{{TargetCharIndex: 0, Type: ActionTypeAttack}})
+
+	//the first attack starts on frame 0 and lands 21 frames later
+	ev := d.Step()
+	if len(ev) == 0 || ev[0].Type != EventActionStart || ev[0].Frame != 0 {
+		t.Fatalf("expected the first attack on frame 0, got %v", ev)
+	}
+	st := d.State()
+	if st.Frame != 1 || st.Active != "Test Pyro" || len(st.Scheduled) != 1 || st.Current == nil {
+		t.Errorf("unexpected state %+v", st)
+	}
+	var hit []Event
+	for len(hit) == 0 {
+		for _, e := range d.Step() {
+			if e.Type == EventDamage {
+				hit = append(hit, e)
+			}
+		}
+	}
+	if hit[0].Frame != 21 || !hit[0].Blocked {
+		t.Errorf("expected the first hit on frame 21 to hit the shield, got %+v", hit[0])
+	}
+
+	//injected actions run next, then the rotation picks up again
+	if err := d.Inject(Action{TargetCharIndex: 0, Type: ActionTypeSkill}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Inject(Action{TargetCharIndex: 5, Type: ActionTypeSkill}); err == nil {
+		t.Errorf("expected an invalid character to fail")
+	}
+	var actions []ActionType
+	for d.State().Frame < 200 {
+		for _, e := range d.Step() {
+			if e.Type == EventActionStart {
+				actions = append(actions, e.Action)
+			}
+		}
+	}
+	if len(actions) < 2 || actions[0] != ActionTypeSkill || actions[1] != ActionTypeAttack || len(d.Injected()) != 0 {
+		t.Errorf("expected the injected skill then attacks, got %v", actions)
+	}
+	st = d.State()
+	if st.Characters[0].Mods["skill"] == nil || st.Characters[0].Cooldowns["skill-cd"] == 0 {
+		t.Errorf("expected the skill mod and cooldown, got %+v", st.Characters[0])
+	}
+	//pyro breaks the cryo shield
+	if st.Enemy.Shield != nil || st.Enemy.Damage == 0 || st.Enemy.Auras[Pyro].Unit != "A" {
+		t.Errorf("expected the shield broken and pyro applied, got %+v", st.Enemy)
+	}
+	if r := d.Result(); r.Frames != 200 || len(r.Actions) != len(actions)+1 {
+		t.Errorf("unexpected result %v frames, %v actions", r.Frames, len(r.Actions))
+	}
+	//the event log keeps going alongside
+	if !bytes.Contains(log.Bytes(), []byte(`"Type":"action_start"`)) {
+		t.Errorf("expected the event log to be written too")
+	}
+}
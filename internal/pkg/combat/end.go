@@ -0,0 +1,109 @@
+package combat
+
+import (
+	"context"
+	"errors"
+	"math"
+)
+
+//FramesPerSecond is the rate the sim runs at
+const FramesPerSecond = 60
+
+//EndCondition decides when a run stops. Any number of limits can be set and
+//the run stops on whichever is reached first
+type EndCondition struct {
+	Seconds   float64 //fight length in seconds; may be fractional
+	Frames    int     //fight length in frames
+	Cycles    int     //full passes through the action list
+	EnemyDead bool    //stop once the enemy's hp is gone; needs Enemy.HP and another limit in case it never dies
+}
+
+//FrameLimit returns the frame a run has to stop on, or -1 if there is no time
+//limit
+func (e EndCondition) FrameLimit() int {
+	f := -1
+	if e.Seconds > 0 {
+		f = int(math.Round(e.Seconds * FramesPerSecond))
+	}
+	if e.Frames > 0 && (f < 0 || e.Frames < f) {
+		f = e.Frames
+	}
+	return f
+}
+
+func (e EndCondition) validate(s *Sim, list []Action) error {
+	if e.Seconds < 0 || e.Frames < 0 || e.Cycles < 0 {
+		return errors.New("end condition limits can't be negative")
+	}
+	if e.Seconds == 0 && e.Frames == 0 && e.Cycles == 0 && !e.EnemyDead {
+		return errors.New("no end condition set")
+	}
+	if e.Cycles > 0 && len(list) == 0 {
+		return errors.New("can't run for a number of cycles without any actions")
+	}
+	if e.EnemyDead && s.Target.HP <= 0 {
+		return errors.New("enemy hp has to be set to run until it's dead")
+	}
+	if e.EnemyDead && e.Seconds == 0 && e.Frames == 0 && e.Cycles == 0 {
+		return errors.New("running until the enemy is dead needs a time or cycle limit as well")
+	}
+	return nil
+}
+
+//maxSettle is the most frames a cycle limited run keeps going after its last
+//pass for what the passes set off to land
+const maxSettle = 30 * FramesPerSecond
+
+//RunUntil runs the sim repeating list until the end condition is met. A run
+//limited by cycles takes no more actions once the last pass is over, but keeps
+//going until everything the passes set off (hits in flight, summons, dots)
+//has landed, or it runs into another limit, so each pass gets all its damage
+func (s *Sim) RunUntil(end EndCondition, list []Action) (Result, error) {
+	return s.RunContext(context.Background(), end, list)
+}
+
+//RunContext is RunUntil that also gives up once ctx is done, returning the
+//result so far along with the context's error. The context is checked once
+//every simulated second
+func (s *Sim) RunContext(ctx context.Context, end EndCondition, list []Action) (Result, error) {
+	if err := end.validate(s, list); err != nil {
+		return Result{}, err
+	}
+	s.emit(Event{Type: EventSimStart, Version: EventVersion})
+	until := end.FrameLimit()
+	rot := &cycle{list: list, limit: end.Cycles}
+	for until < 0 || s.Frame < until {
+		if s.Frame%FramesPerSecond == 0 {
+			if err := ctx.Err(); err != nil {
+				return s.result(), err
+			}
+		}
+		s.step(rot)
+		s.Frame++
+		if rot.over || end.EnemyDead && s.Target.Dead() {
+			break
+		}
+	}
+	if rot.over {
+		settle := s.Frame + maxSettle
+		if until >= 0 && until < settle {
+			settle = until
+		}
+		for s.Frame < settle && s.pending(len(s.stats.Cycles)) && !(end.EnemyDead && s.Target.Dead()) {
+			s.step(rot)
+			s.Frame++
+		}
+	}
+	return s.result(), nil
+}
+
+//pending returns true if anything the first n passes through the action list
+//scheduled is still running
+func (s *Sim) pending(n int) bool {
+	for _, t := range s.actions {
+		if t.cause < n {
+			return true
+		}
+	}
+	return false
+}
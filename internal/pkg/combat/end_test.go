@@ -0,0 +1,156 @@
+package combat
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+//endSim sets up a character whose attack deals a flat 100 damage
+func endSim(t *testing.T, hp float64) *Sim {
+	p := testProfile("Test Pyro")
+	p.Seed = 3
+	p.Enemy.HP = hp
+	s, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Characters[0]
+	c.Attack = func(s *Sim, p ActionParams) int {
+		s.Target.damage += 100
+		return 30
+	}
+	return s
+}
+
+var endRotation = []Action{
+	{TargetCharIndex: 0, Type: ActionTypeAttack},
+	{TargetCharIndex: 0, Type: ActionTypeAttack},
+}
+
+func TestRunUntil(t *testing.T) {
+	cases := []struct {
+		name   string
+		hp     float64
+		end    EndCondition
+		frames int
+	}{
+		{"fractional seconds", 0, EndCondition{Seconds: 1.5}, 90},
+		{"frames", 0, EndCondition{Frames: 77}, 77},
+		{"first of frames and seconds", 0, EndCondition{Seconds: 10, Frames: 50}, 50},
+		//each attack takes 31 frames, so a pass of two takes 62; the frame the
+		//next pass would start on is the last one simulated
+		{"cycles", 0, EndCondition{Cycles: 3}, 187},
+		{"cycles before seconds", 0, EndCondition{Seconds: 60, Cycles: 2}, 125},
+		{"seconds before cycles", 0, EndCondition{Seconds: 1, Cycles: 2}, 60},
+		//the fifth attack starts on frame 124
+		{"enemy dead", 500, EndCondition{Seconds: 60, EnemyDead: true}, 125},
+		{"enemy dead before cycles", 500, EndCondition{EnemyDead: true, Cycles: 10}, 125},
+		{"seconds before enemy dead", 500, EndCondition{Seconds: 1, EnemyDead: true}, 60},
+	}
+	for _, c := range cases {
+		s := endSim(t, c.hp)
+		r, err := s.RunUntil(c.end, endRotation)
+		if err != nil {
+			t.Errorf("%v: %v", c.name, err)
+			continue
+		}
+		if r.Frames != c.frames {
+			t.Errorf("%v: expected the run to stop on frame %v, got %v", c.name, c.frames, r.Frames)
+		}
+	}
+}
+
+func TestRunUntilCycles(t *testing.T) {
+	s := endSim(t, 0)
+	r, err := s.RunUntil(EndCondition{Cycles: 3}, endRotation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Cycles) != 3 {
+		t.Fatalf("expected 3 cycles, got %v", len(r.Cycles))
+	}
+	for i, c := range r.Cycles {
+		if c.Start != 62*i || c.End != 62*(i+1) || c.Damage != 200 {
+			t.Errorf("cycle %v: expected frames %v to %v with 200 damage, got %+v", i, 62*i, 62*(i+1), c)
+		}
+	}
+	if r.DamagePerCycle != 200 || r.CycleDPS != 200*60.0/62 {
+		t.Errorf("expected 200 damage per cycle at %v dps, got %v and %v", 200*60.0/62, r.DamagePerCycle, r.CycleDPS)
+	}
+	if r.Damage != 600 || len(r.Actions) != 6 {
+		t.Errorf("expected exactly 6 attacks, got %v damage from %v actions", r.Damage, len(r.Actions))
+	}
+
+	//a partial pass at the end is left out of the per cycle numbers
+	s = endSim(t, 0)
+	r, _ = s.RunUntil(EndCondition{Frames: 150}, endRotation)
+	if len(r.Cycles) != 2 || r.DamagePerCycle != 200 || r.Damage != 500 {
+		t.Errorf("expected 2 full cycles of 200 damage out of 500, got %v cycles, %v per cycle, %v total", len(r.Cycles), r.DamagePerCycle, r.Damage)
+	}
+}
+
+func TestCycleDamageAttribution(t *testing.T) {
+	s := endSim(t, 0)
+	c := s.Characters[0]
+	//each attack's damage lands well after the next pass has started
+	c.Attack = func(s *Sim, p ActionParams) int {
+		s.AddAction(func(s *Sim, tick int) bool {
+			if tick < 100 {
+				return false
+			}
+			s.Target.damage += 100
+			return true
+		}, fmt.Sprintf("late-hit-%v", s.Frame))
+		return 30
+	}
+	r, err := s.RunUntil(EndCondition{Cycles: 3}, endRotation[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Cycles) != 3 || len(r.Actions) != 3 {
+		t.Fatalf("expected 3 cycles of one attack, got %v cycles and %v actions", len(r.Cycles), len(r.Actions))
+	}
+	for i, c := range r.Cycles {
+		if c.Damage != 100 {
+			t.Errorf("cycle %v: expected the 100 damage it caused, got %+v", i, c)
+		}
+	}
+	//the run goes on past the last pass until its hit has landed
+	last := r.Actions[len(r.Actions)-1].Start
+	if r.Damage != 300 || r.Frames <= last+100 || r.Frames > last+105 {
+		t.Errorf("expected the last hit to land before the run ended, got %v damage over %v frames", r.Damage, r.Frames)
+	}
+}
+
+func TestInvalidEndCondition(t *testing.T) {
+	for _, e := range []EndCondition{
+		{},
+		{Seconds: -1},
+		{Frames: 10, Cycles: -2},
+		{EnemyDead: true},
+	} {
+		if _, err := endSim(t, 0).RunUntil(e, endRotation); err == nil {
+			t.Errorf("expected %+v to be invalid", e)
+		}
+	}
+	if _, err := endSim(t, 0).RunUntil(EndCondition{Cycles: 1}, nil); err == nil {
+		t.Errorf("expected cycles without actions to be invalid")
+	}
+	//an enemy that never dies would run forever
+	if _, err := endSim(t, 500).RunUntil(EndCondition{EnemyDead: true}, endRotation); err == nil {
+		t.Errorf("expected enemy dead without a time or cycle limit to be invalid")
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, err := endSim(t, 0).RunContext(ctx, EndCondition{Seconds: 10}, endRotation)
+	if err != context.Canceled {
+		t.Errorf("expected the run to be cancelled, got %v", err)
+	}
+	if r.Frames != 0 {
+		t.Errorf("expected the run to stop straight away, got %v frames", r.Frames)
+	}
+}
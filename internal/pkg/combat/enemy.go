@@ -21,14 +21,23 @@ const (
 type Enemy struct {
 	Level  int64
 	Resist float64
+	HP     float64 //0 if the enemy can't die
 
 	//resist mods
 	ResMod map[string]float64
 
+	log *zap.SugaredLogger
+
 	//tracking
 	auras  map[eleType]aura
 	status map[string]int //countdown to how long status last
 
+	attacks []EnemyAttack
+
+	//elemental shield; nil if there is none or it's broken
+	shield       *enemyShield
+	shieldBroken int
+
 	//stats
 	damage float64 //total damage received
 }
@@ -51,43 +60,214 @@ func auraDur(unit string, gauge float64) int {
 	return 0
 }
 
-//applyAura applies an aura to the Unit, can trigger damage for superconduct, electrocharged, etc..
-func (e *Enemy) applyAura(ds snapshot) {
-	//1A = 9.5s (570 frames) per unit, 2B = 6s (360 frames) per unit, 4C = 4.25s (255 frames) per unit
-	//loop through existing auras and apply reactions if any
-	if len(e.auras) > 1 {
-		//this case should only happen with electro charge where there's 2 aura active at any one point in time
-		for ele, a := range e.auras {
-			if ele != ds.Element {
-				zap.S().Debugw("apply aura", "aura", a, "existing ele", ele, "next ele", ds.Element)
-			} else {
-				zap.S().Debugf("not implemented!!!")
-			}
+//reactionType is a string representing an elemental reaction
+type reactionType string
+
+//reactions
+const (
+	Melt           reactionType = "melt"
+	Vaporize       reactionType = "vaporize"
+	Overload       reactionType = "overload"
+	Superconduct   reactionType = "superconduct"
+	ElectroCharged reactionType = "electrocharged"
+	Freeze         reactionType = "freeze"
+	Swirl          reactionType = "swirl"
+	Crystallize    reactionType = "crystallize"
+)
+
+//auraOrder is the order existing auras are checked in when more than one is
+//present (i.e. electro charged) so that reactions are deterministic
+var auraOrder = []eleType{Frozen, Electro, Hydro, Pyro, Cryo}
+
+//react returns the reaction triggered by applying ele on top of an existing aura
+func react(existing, ele eleType) reactionType {
+	switch ele {
+	case Anemo:
+		return Swirl
+	case Geo:
+		return Crystallize
+	case Pyro:
+		switch existing {
+		case Cryo, Frozen:
+			return Melt
+		case Hydro:
+			return Vaporize
+		case Electro:
+			return Overload
 		}
-	} else if len(e.auras) == 1 {
-		if a, ok := e.auras[ds.Element]; ok {
-			next := aura{
-				gauge:    ds.AuraGauge,
-				unit:     a.unit,
-				duration: auraDur(a.unit, ds.AuraGauge),
-			}
-			//refresh duration
-			zap.S().Debugf("%v refreshed. unit: %v. new duration: %v", ds.Element, a.unit, next.duration)
-			e.auras[ds.Element] = next
-		} else {
-			//apply reaction
-			//The length of the freeze is based on the lowest remaining duration of the two elements applied.
-			zap.S().Debugf("not implemented!!!")
+	case Hydro:
+		switch existing {
+		case Pyro:
+			return Vaporize
+		case Cryo:
+			return Freeze
+		case Electro:
+			return ElectroCharged
+		}
+	case Cryo:
+		switch existing {
+		case Pyro:
+			return Melt
+		case Hydro:
+			return Freeze
+		case Electro:
+			return Superconduct
+		}
+	case Electro:
+		switch existing {
+		case Pyro:
+			return Overload
+		case Cryo, Frozen:
+			return Superconduct
+		case Hydro:
+			return ElectroCharged
+		}
+	}
+	return ""
+}
+
+//reaction returns the existing aura that applying ele would react with and the
+//reaction triggered, checking auras in auraOrder; nothing reacts with an aura of
+//its own element, which is refreshed instead
+func (e *Enemy) reaction(ele eleType) (eleType, reactionType) {
+	if ele == Physical {
+		return "", ""
+	}
+	if _, ok := e.auras[ele]; ok {
+		return "", ""
+	}
+	for _, existing := range auraOrder {
+		if _, ok := e.auras[existing]; !ok {
+			continue
+		}
+		if r := react(existing, ele); r != "" {
+			return existing, r
+		}
+	}
+	return "", ""
+}
+
+//gaugeMult returns how many units of the existing aura each unit of the
+//triggering element consumes; forward melt/vaporize (pyro on cryo, hydro on
+//pyro) is 2x, the reverse 0.5x, swirl and crystallize 0.5x and the rest 1x
+func gaugeMult(r reactionType, ele eleType) float64 {
+	switch r {
+	case Melt:
+		if ele == Pyro {
+			return 2
+		}
+		return 0.5
+	case Vaporize:
+		if ele == Hydro {
+			return 2
+		}
+		return 0.5
+	case Swirl, Crystallize:
+		return 0.5
+	}
+	return 1
+}
+
+//ampMult returns the base damage multiplier for an amplifying reaction (2x for
+//forward melt/vaporize, 1.5x for the reverse) or 0 if the reaction doesn't
+//amplify damage
+func ampMult(r reactionType, ele eleType) float64 {
+	switch r {
+	case Melt:
+		if ele == Pyro {
+			return 2
 		}
-	} else {
-		next := aura{
-			gauge:    ds.AuraGauge,
-			unit:     ds.AuraUnit,
-			duration: auraDur(ds.AuraUnit, ds.AuraGauge),
+		return 1.5
+	case Vaporize:
+		if ele == Hydro {
+			return 2
 		}
-		zap.S().Debugf("%v applied (new). unit: %v. duration: %v", ds.Element, next.unit, next.duration)
+		return 1.5
+	}
+	return 0
+}
+
+//amplifier returns the amplifying multiplier the hit would get from the
+//reaction it triggers, without applying its aura
+func (e *Enemy) amplifier(ds snapshot) float64 {
+	_, r := e.reaction(ds.Element)
+	return ampMult(r, ds.Element)
+}
+
+//remaining returns the gauge left on the aura; gauge decays linearly over the
+//aura's duration
+func (a aura) remaining() float64 {
+	full := auraDur(a.unit, a.gauge)
+	if full == 0 {
+		return 0
+	}
+	return a.gauge * float64(a.duration) / float64(full)
+}
+
+//consume removes gauge from the aura, shortening its duration to match, and
+//returns false if there's nothing left of it
+func (a *aura) consume(gauge float64) bool {
+	rem := a.remaining()
+	if gauge >= rem {
+		return false
+	}
+	a.duration = int(float64(a.duration) * (rem - gauge) / rem)
+	return a.duration > 0
+}
+
+//applyAura applies an aura to the Unit, returning the reaction triggered if any.
+//a reaction consumes the existing aura's gauge by the applied gauge times the
+//reaction's gauge multiplier, leaving whatever's left of it
+func (e *Enemy) applyAura(ds snapshot) reactionType {
+	//1A = 9.5s (570 frames) per unit, 2B = 6s (360 frames) per unit, 4C = 4.25s (255 frames) per unit
+	next := aura{
+		gauge:    ds.AuraGauge,
+		unit:     ds.AuraUnit,
+		duration: auraDur(ds.AuraUnit, ds.AuraGauge),
+	}
+	//physical never reacts; anemo and geo can react but never stay as an aura
+	if ds.Element == Physical {
+		return ""
+	}
+	if a, ok := e.auras[ds.Element]; ok {
+		next.unit = a.unit
+		next.duration = auraDur(a.unit, ds.AuraGauge)
+		//refresh duration
+		e.log.Debugf("%v refreshed. unit: %v. new duration: %v", ds.Element, a.unit, next.duration)
 		e.auras[ds.Element] = next
+		return ""
 	}
+	ele, r := e.reaction(ds.Element)
+	if r != "" {
+		a := e.auras[ele]
+		e.log.Debugf("%v applied on %v, triggered %v", ds.Element, ele, r)
+		switch r {
+		case ElectroCharged:
+			//hydro and electro coexist
+			e.auras[ds.Element] = next
+		case Freeze:
+			//The length of the freeze is based on the lowest remaining duration of the two elements applied.
+			if next.duration < a.duration {
+				a.duration = next.duration
+			}
+			delete(e.auras, ele)
+			e.auras[Frozen] = a
+		default:
+			if a.consume(ds.AuraGauge * gaugeMult(r, ds.Element)) {
+				e.log.Debugf("%v consumed, remaining duration: %v", ele, a.duration)
+				e.auras[ele] = a
+			} else {
+				delete(e.auras, ele)
+			}
+		}
+		return r
+	}
+	if ds.Element == Anemo || ds.Element == Geo {
+		return ""
+	}
+	e.log.Debugf("%v applied (new). unit: %v. duration: %v", ds.Element, next.unit, next.duration)
+	e.auras[ds.Element] = next
+	return ""
 }
 
 func (e *Enemy) tick(s *Sim) {
@@ -102,7 +282,8 @@ func (e *Enemy) tick(s *Sim) {
 	//tick down aura
 	for k, v := range e.auras {
 		if v.duration == 0 {
-			print(s.Frame, true, "aura %v expired", k)
+			s.print(true, "aura %v expired", k)
+			s.emit(Event{Type: EventAuraExpired, Element: k})
 			delete(e.auras, k)
 		} else {
 			a := e.auras[k]
@@ -111,3 +292,26 @@ func (e *Enemy) tick(s *Sim) {
 		}
 	}
 }
+
+//AddStatus adds a status (i.e. petrified) to the enemy lasting dur frames
+func (e *Enemy) AddStatus(key string, dur int) {
+	e.status[key] = dur
+}
+
+//Dead returns whether the enemy has taken damage equal to its hp
+func (e *Enemy) Dead() bool {
+	return e.HP > 0 && e.damage >= e.HP
+}
+
+//cryoAffected returns true if the enemy is frozen or has a cryo aura
+func (e *Enemy) cryoAffected() bool {
+	_, frozen := e.auras[Frozen]
+	_, cryo := e.auras[Cryo]
+	return frozen || cryo
+}
+
+//HasStatus returns true if the enemy currently has the given status
+func (e *Enemy) HasStatus(key string) bool {
+	_, ok := e.status[key]
+	return ok
+}
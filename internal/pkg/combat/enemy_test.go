@@ -0,0 +1,142 @@
+package combat
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestReact(t *testing.T) {
+	cases := []struct {
+		existing, ele eleType
+		r             reactionType
+	}{
+		{Cryo, Pyro, Melt},
+		{Pyro, Cryo, Melt},
+		{Hydro, Pyro, Vaporize},
+		{Electro, Pyro, Overload},
+		{Electro, Cryo, Superconduct},
+		{Hydro, Electro, ElectroCharged},
+		{Hydro, Cryo, Freeze},
+		{Pyro, Anemo, Swirl},
+		{Electro, Geo, Crystallize},
+		{Pyro, Pyro, ""},
+	}
+	for _, c := range cases {
+		if r := react(c.existing, c.ele); r != c.r {
+			t.Errorf("%v on %v: expected %v got %v", c.ele, c.existing, c.r, r)
+		}
+	}
+}
+
+func TestApplyAura(t *testing.T) {
+	hit := func(e eleType, gauge float64, unit string) snapshot {
+		return snapshot{Element: e, ApplyAura: true, AuraGauge: gauge, AuraUnit: unit}
+	}
+	auras := func(e *Enemy) map[eleType]bool {
+		r := make(map[eleType]bool)
+		for k := range e.auras {
+			r[k] = true
+		}
+		return r
+	}
+	cases := []struct {
+		name  string
+		hits  []snapshot
+		r     reactionType //reaction of the last hit
+		auras []eleType    //auras left afterwards
+	}{
+		{"new aura", []snapshot{hit(Cryo, 1, "A")}, "", []eleType{Cryo}},
+		{"refresh", []snapshot{hit(Cryo, 1, "A"), hit(Cryo, 2, "B")}, "", []eleType{Cryo}},
+		{"melt consumes", []snapshot{hit(Cryo, 1, "A"), hit(Pyro, 1, "A")}, Melt, nil},
+		{"electro charged coexists", []snapshot{hit(Hydro, 1, "A"), hit(Electro, 1, "A")}, ElectroCharged, []eleType{Hydro, Electro}},
+		{"freeze", []snapshot{hit(Hydro, 1, "A"), hit(Cryo, 1, "A")}, Freeze, []eleType{Frozen}},
+		{"superconduct on frozen", []snapshot{hit(Hydro, 1, "A"), hit(Cryo, 1, "A"), hit(Electro, 1, "A")}, Superconduct, nil},
+		{"swirl consumes", []snapshot{hit(Pyro, 1, "A"), hit(Anemo, 2, "B")}, Swirl, nil},
+		{"swirl leaves gauge", []snapshot{hit(Pyro, 1, "A"), hit(Anemo, 1, "A")}, Swirl, []eleType{Pyro}},
+		{"reverse melt leaves gauge", []snapshot{hit(Pyro, 2, "B"), hit(Cryo, 1, "A")}, Melt, []eleType{Pyro}},
+		{"forward vaporize consumes", []snapshot{hit(Pyro, 2, "B"), hit(Hydro, 1, "A")}, Vaporize, nil},
+		{"anemo never stays", []snapshot{hit(Anemo, 1, "A")}, "", nil},
+		{"physical never reacts", []snapshot{hit(Cryo, 1, "A"), hit(Physical, 1, "A")}, "", []eleType{Cryo}},
+	}
+	for _, c := range cases {
+		e := &Enemy{auras: make(map[eleType]aura), log: zap.NewNop().Sugar()}
+		var r reactionType
+		for _, h := range c.hits {
+			r = e.applyAura(h)
+		}
+		if r != c.r {
+			t.Errorf("%v: expected %q, got %q", c.name, c.r, r)
+		}
+		got := auras(e)
+		if len(got) != len(c.auras) {
+			t.Errorf("%v: expected auras %v, got %v", c.name, c.auras, got)
+			continue
+		}
+		for _, a := range c.auras {
+			if !got[a] {
+				t.Errorf("%v: expected auras %v, got %v", c.name, c.auras, got)
+			}
+		}
+	}
+
+	//the freeze lasts as long as the shorter of the two auras
+	e := &Enemy{auras: make(map[eleType]aura), log: zap.NewNop().Sugar()}
+	e.applyAura(hit(Hydro, 1, "A"))
+	e.applyAura(hit(Cryo, 1, "B"))
+	if d := e.auras[Frozen].duration; d != auraDur("B", 1) {
+		t.Errorf("expected frozen to last %v frames, got %v", auraDur("B", 1), d)
+	}
+}
+
+func TestAuraGauge(t *testing.T) {
+	hit := func(e eleType, gauge float64, unit string) snapshot {
+		return snapshot{Element: e, ApplyAura: true, AuraGauge: gauge, AuraUnit: unit}
+	}
+	//2B pyro with 0.5 consumed by a reverse melt has 1.5 units, 3/4 of its duration, left
+	e := &Enemy{auras: make(map[eleType]aura), log: zap.NewNop().Sugar()}
+	e.applyAura(hit(Pyro, 2, "B"))
+	e.applyAura(hit(Cryo, 1, "A"))
+	if d := e.auras[Pyro].duration; d != auraDur("B", 2)*3/4 {
+		t.Errorf("expected pyro to last %v frames, got %v", auraDur("B", 2)*3/4, d)
+	}
+	if g := e.auras[Pyro].remaining(); g != 1.5 {
+		t.Errorf("expected 1.5 units of pyro left, got %v", g)
+	}
+
+	//gauge decays with the aura, so half way through 1A pyro a 1A swirl
+	//(0.5 units) uses up the rest of it
+	e = &Enemy{auras: make(map[eleType]aura), log: zap.NewNop().Sugar()}
+	e.applyAura(hit(Pyro, 1, "A"))
+	a := e.auras[Pyro]
+	a.duration /= 2
+	e.auras[Pyro] = a
+	e.applyAura(hit(Anemo, 1, "A"))
+	if _, ok := e.auras[Pyro]; ok {
+		t.Errorf("expected the swirl to use up the decayed pyro")
+	}
+}
+
+func TestAmplifier(t *testing.T) {
+	hit := func(e eleType) snapshot {
+		return snapshot{Element: e, ApplyAura: true, AuraGauge: 1, AuraUnit: "A"}
+	}
+	cases := []struct {
+		existing, ele eleType
+		mult          float64
+	}{
+		{Cryo, Pyro, 2},
+		{Pyro, Cryo, 1.5},
+		{Pyro, Hydro, 2},
+		{Hydro, Pyro, 1.5},
+		{Electro, Pyro, 0},
+		{Pyro, Pyro, 0},
+	}
+	for _, c := range cases {
+		e := &Enemy{auras: make(map[eleType]aura), log: zap.NewNop().Sugar()}
+		e.applyAura(hit(c.existing))
+		if m := e.amplifier(hit(c.ele)); m != c.mult {
+			t.Errorf("%v on %v: expected %v got %v", c.ele, c.existing, c.mult, m)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package combat
+
+//EnemyShieldProfile describes an elemental shield the enemy starts with, i.e.
+//an abyss mage or herald shield
+type EnemyShieldProfile struct {
+	Element eleType `yaml:"Element"`
+	Gauge   float64 `yaml:"Gauge"` //elemental gauge; if set the shield breaks once it's depleted
+	HP      float64 `yaml:"HP"`    //if set the shield also breaks once depleted; needed without a gauge
+	//gauge and damage multipliers by attacking element; overrides the defaults
+	Multipliers map[eleType]float64 `yaml:"Multipliers"`
+}
+
+//shieldCounters is the element each shield element is weak to
+var shieldCounters = map[eleType]eleType{
+	Pyro:    Hydro,
+	Hydro:   Cryo,
+	Cryo:    Pyro,
+	Electro: Pyro,
+}
+
+type enemyShield struct {
+	EnemyShieldProfile
+	gauge float64
+	hp    float64
+}
+
+func newEnemyShield(p EnemyShieldProfile) *enemyShield {
+	return &enemyShield{
+		EnemyShieldProfile: p,
+		gauge:              p.Gauge,
+		hp:                 p.HP,
+	}
+}
+
+//multiplier returns how effective the element is against the shield; it's
+//immune to its own element and takes double from the element countering it
+func (e *enemyShield) multiplier(ele eleType) float64 {
+	if m, ok := e.Multipliers[ele]; ok {
+		return m
+	}
+	switch {
+	case ele == e.Element:
+		return 0
+	case shieldCounters[e.Element] == ele:
+		return 2
+	}
+	return 1
+}
+
+//hit applies damage and gauge (if the hit applies an aura) to the shield,
+//returns true if the shield broke
+func (e *enemyShield) hit(ds snapshot, damage float64) bool {
+	m := e.multiplier(ds.Element)
+	if e.Gauge > 0 && ds.ApplyAura && ds.Element != Physical {
+		e.gauge -= ds.AuraGauge * m
+	}
+	if e.HP > 0 {
+		e.hp -= damage * m
+	}
+	return (e.Gauge > 0 && e.gauge <= 0) || (e.HP > 0 && e.hp <= 0)
+}
+
+//shieldHit sends the hit to the enemy's elemental shield if it has one up;
+//returns true if the hit was blocked
+func (s *Sim) shieldHit(ds snapshot, damage float64) bool {
+	e := s.Target
+	if e.shield == nil {
+		return false
+	}
+	broke := e.shield.hit(ds, damage)
+	s.print(true, "%v - %v hit %v shield, gauge: %.2f, hp: %.0f", ds.CharName, ds.Abil, e.shield.Element, e.shield.gauge, e.shield.hp)
+	if broke {
+		s.print(false, "%v shield broken by %v - %v", e.shield.Element, ds.CharName, ds.Abil)
+		e.shield = nil
+		e.shieldBroken = s.Frame
+	}
+	return true
+}
+
+//HasShield returns true if the enemy's elemental shield is still up
+func (e *Enemy) HasShield() bool {
+	return e.shield != nil
+}
+
+//ShieldBrokenAt returns the frame the enemy's elemental shield broke, or -1 if
+//it hasn't (or never had one)
+func (e *Enemy) ShieldBrokenAt() int {
+	return e.shieldBroken
+}
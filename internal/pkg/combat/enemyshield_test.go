@@ -0,0 +1,83 @@
+package combat
+
+import "testing"
+
+func TestEnemyShield(t *testing.T) {
+	p := testProfile("Test Pyro")
+	p.Enemy.Shield = &EnemyShieldProfile{Element: Cryo, Gauge: 4}
+	s, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hit := func(e eleType, gauge float64) float64 {
+		d := s.Characters[0].Snapshot(e)
+		d.Mult = 1
+		d.ApplyAura = gauge > 0
+		d.AuraGauge = gauge
+		d.AuraUnit = "A"
+		return s.ApplyDamage(d)
+	}
+
+	//own element does nothing, physical has no gauge
+	hit(Cryo, 4)
+	hit(Physical, 0)
+	if !s.Target.HasShield() || s.Target.shield.gauge != 4 {
+		t.Fatalf("expected untouched shield, got %+v", s.Target.shield)
+	}
+	//shield blocks damage and auras
+	if dmg := hit(Hydro, 1); dmg != 0 || s.Target.damage != 0 || len(s.Target.auras) != 0 {
+		t.Errorf("expected shield to block damage and aura, got %v %v %v", dmg, s.Target.damage, s.Target.auras)
+	}
+	if s.Target.shield.gauge != 3 {
+		t.Errorf("expected gauge 3 after 1 hydro, got %v", s.Target.shield.gauge)
+	}
+	//pyro counters cryo shields for double gauge
+	hit(Pyro, 1)
+	if s.Target.shield.gauge != 1 {
+		t.Errorf("expected gauge 1 after 1 pyro, got %v", s.Target.shield.gauge)
+	}
+	s.Frame = 42
+	hit(Pyro, 1)
+	if s.Target.HasShield() || s.Target.ShieldBrokenAt() != 42 {
+		t.Fatalf("expected shield broken at frame 42, got %v", s.Target.ShieldBrokenAt())
+	}
+	if dmg := hit(Pyro, 1); dmg == 0 || s.Target.damage != dmg {
+		t.Errorf("expected damage to go through after the shield broke, got %v", dmg)
+	}
+}
+
+func TestEnemyShieldHP(t *testing.T) {
+	e := newEnemyShield(EnemyShieldProfile{Element: Hydro, Gauge: 10, HP: 1000, Multipliers: map[eleType]float64{Physical: 0.5}})
+	if e.hit(snapshot{Element: Physical}, 1000) {
+		t.Errorf("physical at 50%% shouldn't break a 1000 hp shield")
+	}
+	if e.hp != 500 {
+		t.Errorf("expected 500 hp left, got %v", e.hp)
+	}
+	if !e.hit(snapshot{Element: Cryo}, 250) {
+		t.Errorf("cryo at 200%% should break the remaining 500 hp")
+	}
+	if m := e.multiplier(Hydro); m != 0 {
+		t.Errorf("expected immunity to hydro, got %v", m)
+	}
+}
+
+func TestEnemyShieldHPOnly(t *testing.T) {
+	//without a gauge only hp breaks the shield
+	e := newEnemyShield(EnemyShieldProfile{Element: Hydro, HP: 1000})
+	if e.hit(snapshot{Element: Cryo, ApplyAura: true, AuraGauge: 4}, 100) {
+		t.Errorf("hp only shield broke on the first hit")
+	}
+	if e.hp != 800 {
+		t.Errorf("expected 800 hp left, got %v", e.hp)
+	}
+	if !e.hit(snapshot{Element: Pyro}, 800) {
+		t.Errorf("expected the shield to break once its hp is gone")
+	}
+
+	p := testProfile("Test Pyro")
+	p.Enemy.Shield = &EnemyShieldProfile{Element: Cryo}
+	if _, err := New(p); err == nil {
+		t.Errorf("expected error on a shield without gauge or hp")
+	}
+}
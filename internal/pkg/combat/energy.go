@@ -0,0 +1,23 @@
+package combat
+
+//GenerateParticles gives energy to every character from n elemental particles
+//of the given element. Each particle gives 3 energy to characters of the same
+//element and 1 otherwise; off field characters only receive 60%. All scaled
+//by the character's energy recharge
+func (s *Sim) GenerateParticles(e eleType, n int) {
+	for i, c := range s.Characters {
+		amt := 1.0
+		if c.Element == e {
+			amt = 3
+		}
+		if i != s.Active {
+			amt = amt * 0.6
+		}
+		amt = amt * float64(n) * (1 + c.Stats[ER])
+		c.Energy += amt
+		if c.Energy > c.MaxEnergy {
+			c.Energy = c.MaxEnergy
+		}
+		s.print(true, "%v received %.2f energy from %v %v particles, now %.2f", c.Profile.Name, amt, n, e, c.Energy)
+	}
+}
@@ -0,0 +1,261 @@
+package combat
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+//EventVersion is the version of the event schema; bump it whenever a field
+//changes meaning or is removed
+const EventVersion = 2
+
+//EventType identifies what happened
+type EventType string
+
+//EventType constants
+const (
+	EventSimStart      EventType = "sim_start"      //Version
+	EventActionStart   EventType = "action_start"   //Char, Action, Params
+	EventActionEnd     EventType = "action_end"     //Char, Action, Frames (how long it took)
+	EventSwap          EventType = "swap"           //Char (swapped to), From
+	EventDamage        EventType = "damage"         //Char, Abil, Element, Damage, Crit, Blocked, Detail
+	EventAuraApplied   EventType = "aura_applied"   //Element
+	EventAuraRefreshed EventType = "aura_refreshed" //Element
+	EventAuraExpired   EventType = "aura_expired"   //Element
+	EventReaction      EventType = "reaction"       //Char, Abil, Element (applied), Reaction
+	EventBuffApplied   EventType = "buff_applied"   //Source, Key, Char for character mods
+	EventBuffExpired   EventType = "buff_expired"   //Source, Key, Char for character mods
+	EventEnergy        EventType = "energy"         //Char, Energy, Amount
+)
+
+//Event is one line of the event log. Only the fields relevant to the type are
+//set; see the EventType constants
+type Event struct {
+	Frame    int           `json:"Frame"`
+	Type     EventType     `json:"Type"`
+	Version  int           `json:"Version,omitempty"`
+	Char     string        `json:"Char,omitempty"`
+	From     string        `json:"From,omitempty"`
+	Action   ActionType    `json:"Action,omitempty"`
+	Params   ActionParams  `json:"Params,omitempty"`
+	Frames   int           `json:"Frames,omitempty"`
+	Abil     string        `json:"Abil,omitempty"`
+	Element  eleType       `json:"Element,omitempty"`
+	Reaction reactionType  `json:"Reaction,omitempty"`
+	Damage   float64       `json:"Damage,omitempty"`
+	Crit     bool          `json:"Crit,omitempty"`
+	Blocked  bool          `json:"Blocked,omitempty"` //hit an enemy elemental shield
+	Detail   *DamageDetail `json:"Detail,omitempty"`
+	Source   string        `json:"Source,omitempty"` //mod, status, or field
+	Key      string        `json:"Key,omitempty"`
+	Energy   *float64      `json:"Energy,omitempty"` //set for energy events, even if 0
+	Amount   float64       `json:"Amount,omitempty"`
+}
+
+//DamageDetail is the breakdown of the hit behind a damage event, with the
+//attacker's stats and the enemy side modifiers as they were when it landed
+type DamageDetail struct {
+	AbilType     ActionType           `json:"AbilType"`
+	Mult         float64              `json:"Mult"`
+	FlatDmg      float64              `json:"FlatDmg,omitempty"`
+	OtherMult    float64              `json:"OtherMult,omitempty"`
+	AmpMult      float64              `json:"AmpMult,omitempty"` //melt/vaporize multiplier
+	HitWeakPoint bool                 `json:"HitWeakPoint,omitempty"`
+	TravelFrames int                  `json:"TravelFrames,omitempty"`
+	ApplyAura    bool                 `json:"ApplyAura,omitempty"`
+	AuraGauge    float64              `json:"AuraGauge,omitempty"`
+	AuraUnit     string               `json:"AuraUnit,omitempty"`
+	SnapshotAt   int                  `json:"SnapshotAt"` //frame the stats were snapshotted on
+	CharLvl      int64                `json:"CharLvl"`
+	BaseAtk      float64              `json:"BaseAtk"`
+	BaseDef      float64              `json:"BaseDef"`
+	BaseHP       float64              `json:"BaseHP"`
+	Stats        map[StatType]float64 `json:"Stats"`
+	DmgBonus     float64              `json:"DmgBonus"` //on top of the elemental and dmg% stats
+	ReactBonus   float64              `json:"ReactBonus,omitempty"`
+	TargetLvl    int64                `json:"TargetLvl"`
+	TargetRes    float64              `json:"TargetRes"`
+	DefMod       float64              `json:"DefMod"`
+	ResMod       float64              `json:"ResMod"`
+}
+
+//damageDetail returns the event breakdown of the snapshot
+func damageDetail(ds snapshot) *DamageDetail {
+	return &DamageDetail{
+		AbilType:     ds.AbilType,
+		Mult:         ds.Mult,
+		FlatDmg:      ds.FlatDmg,
+		OtherMult:    ds.OtherMult,
+		AmpMult:      ds.AmpMult,
+		HitWeakPoint: ds.HitWeakPoint,
+		TravelFrames: ds.TravelFrames,
+		ApplyAura:    ds.ApplyAura,
+		AuraGauge:    ds.AuraGauge,
+		AuraUnit:     ds.AuraUnit,
+		SnapshotAt:   ds.Frame,
+		CharLvl:      ds.CharLvl,
+		BaseAtk:      ds.BaseAtk,
+		BaseDef:      ds.BaseDef,
+		BaseHP:       ds.BaseHP,
+		Stats:        ds.Stats,
+		DmgBonus:     ds.DmgBonus,
+		ReactBonus:   ds.ReactBonus,
+		TargetLvl:    ds.TargetLvl,
+		TargetRes:    ds.TargetRes,
+		DefMod:       ds.DefMod,
+		ResMod:       ds.ResMod,
+	}
+}
+
+//eventLog writes events as newline delimited json and/or hands them to a
+//watcher, and tracks the state needed to turn per frame changes into events
+type eventLog struct {
+	enc    *json.Encoder
+	watch  func(e Event)
+	buffs  map[string]Event //active buffs by source/char/key
+	energy []float64
+}
+
+//LogEvents writes every event of the following runs to w as newline delimited
+//json. Pass nil to stop logging
+func (s *Sim) LogEvents(w io.Writer) {
+	if w == nil {
+		s.eventLog().enc = nil
+		s.dropEventLog()
+		return
+	}
+	s.eventLog().enc = json.NewEncoder(w)
+}
+
+//WatchEvents calls f with every event of the following runs as it happens,
+//alongside any event log. Pass nil to stop watching
+func (s *Sim) WatchEvents(f func(e Event)) {
+	s.eventLog().watch = f
+	s.dropEventLog()
+}
+
+func (s *Sim) eventLog() *eventLog {
+	if s.events == nil {
+		s.events = &eventLog{buffs: make(map[string]Event)}
+	}
+	return s.events
+}
+
+//dropEventLog stops tracking events once nothing is listening
+func (s *Sim) dropEventLog() {
+	if s.events != nil && s.events.enc == nil && s.events.watch == nil {
+		s.events = nil
+	}
+}
+
+func (s *Sim) emit(e Event) {
+	if s.events == nil {
+		return
+	}
+	e.Frame = s.Frame
+	if s.events.watch != nil {
+		s.events.watch(e)
+	}
+	if s.events.enc == nil {
+		return
+	}
+	if err := s.events.enc.Encode(e); err != nil {
+		s.log.Errorw("writing event log failed, no more events will be logged", "err", err)
+		s.events.enc = nil
+		s.dropEventLog()
+	}
+}
+
+//trackEvents emits the buff and energy changes since the last frame
+func (s *Sim) trackEvents() {
+	l := s.events
+	if l == nil {
+		return
+	}
+	active := make(map[string]Event)
+	for _, c := range s.Characters {
+		for k := range c.Mods {
+			active["mod/"+c.Profile.Name+"/"+k] = Event{Source: "mod", Char: c.Profile.Name, Key: k}
+		}
+	}
+	for k := range s.Target.status {
+		active["status/"+k] = Event{Source: "status", Key: k}
+	}
+	for k := range s.fields {
+		active["field/"+k] = Event{Source: "field", Key: k}
+	}
+
+	var keys []string
+	for k := range active {
+		if _, ok := l.buffs[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		e := active[k]
+		e.Type = EventBuffApplied
+		s.emit(e)
+	}
+	keys = keys[:0]
+	for k := range l.buffs {
+		if _, ok := active[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		e := l.buffs[k]
+		e.Type = EventBuffExpired
+		s.emit(e)
+	}
+	if s.events == nil {
+		return
+	}
+	l.buffs = active
+
+	if l.energy == nil {
+		l.energy = make([]float64, len(s.Characters))
+		for i, c := range s.Characters {
+			l.energy[i] = c.Energy
+		}
+	}
+	for i, c := range s.Characters {
+		if c.Energy != l.energy[i] {
+			energy := c.Energy
+			s.emit(Event{Type: EventEnergy, Char: c.Profile.Name, Energy: &energy, Amount: energy - l.energy[i]})
+			l.energy[i] = c.Energy
+		}
+	}
+}
+
+//auraKeys returns the elements currently on the enemy
+func (e *Enemy) auraKeys() map[eleType]bool {
+	r := make(map[eleType]bool, len(e.auras))
+	for k := range e.auras {
+		r[k] = true
+	}
+	return r
+}
+
+//emitAuras emits the aura changes caused by applying the snapshot's element
+func (s *Sim) emitAuras(ds snapshot, before map[eleType]bool) {
+	if s.events == nil {
+		return
+	}
+	if ds.Reaction != "" {
+		s.emit(Event{Type: EventReaction, Char: ds.CharName, Abil: ds.Abil, Element: ds.Element, Reaction: ds.Reaction})
+	}
+	for _, ele := range auraOrder {
+		if _, ok := s.Target.auras[ele]; !ok {
+			continue
+		}
+		switch {
+		case !before[ele]:
+			s.emit(Event{Type: EventAuraApplied, Element: ele})
+		case ele == ds.Element:
+			s.emit(Event{Type: EventAuraRefreshed, Element: ele})
+		}
+	}
+}
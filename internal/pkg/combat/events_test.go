@@ -0,0 +1,88 @@
+package combat
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestEventLog(t *testing.T) {
+	s, err := New(testProfile("Test Pyro", "Test Cryo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hit := func(c *Character, e eleType) func(s *Sim, p ActionParams) int {
+		return func(s *Sim, p ActionParams) int {
+			d := c.Snapshot(e)
+			d.Abil = "Test"
+			d.Mult = 1
+			d.ApplyAura = true
+			d.AuraGauge = 1
+			d.AuraUnit = "A"
+			s.ApplyDamage(d)
+			c.Energy = 0
+			c.Mods["test-buff"] = map[StatType]float64{}
+			return 60
+		}
+	}
+	s.Characters[0].Attack = hit(s.Characters[0], Pyro)
+	s.Characters[1].Attack = hit(s.Characters[1], Cryo)
+	s.Characters[0].Energy = 10
+
+	var buf bytes.Buffer
+	s.LogEvents(&buf)
+	s.Run(5, []Action{{TargetCharIndex: 0, Type: ActionTypeAttack}, {TargetCharIndex: 1, Type: ActionTypeAttack}})
+
+	var events []Event
+	counts := make(map[EventType]int)
+	sc := bufio.NewScanner(&buf)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var e Event
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("invalid event line %q: %v", sc.Text(), err)
+		}
+		events = append(events, e)
+		counts[e.Type]++
+	}
+	if len(events) == 0 || events[0].Type != EventSimStart || events[0].Version != EventVersion {
+		t.Fatalf("expected log to start with sim_start, got %+v", events)
+	}
+	last := 0
+	for _, e := range events {
+		if e.Frame < last {
+			t.Errorf("events out of order: %+v after frame %v", e, last)
+		}
+		last = e.Frame
+		if e.Type == EventDamage && (e.Detail == nil || e.Detail.Mult != 1 || e.Detail.Stats == nil) {
+			t.Errorf("damage event missing detail: %+v", e)
+		}
+		if e.Type == EventEnergy && (e.Energy == nil || *e.Energy != 0 || e.Amount != -10) {
+			t.Errorf("unexpected energy event %+v", e)
+		}
+	}
+	for _, typ := range []EventType{EventActionStart, EventActionEnd, EventSwap, EventDamage, EventAuraApplied, EventReaction, EventBuffApplied, EventEnergy} {
+		if counts[typ] == 0 {
+			t.Errorf("expected at least one %v event, got %v", typ, counts)
+		}
+	}
+	if counts[EventActionStart] < counts[EventActionEnd] || counts[EventActionStart]-counts[EventActionEnd] > 1 {
+		t.Errorf("unmatched action start/end events: %v", counts)
+	}
+}
+
+func TestContinueEventLog(t *testing.T) {
+	s, err := New(testProfile("Test Pyro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Run(1, []Action{{TargetCharIndex: 0, Type: ActionTypeAttack}})
+
+	var events []Event
+	s.WatchEvents(func(e Event) { events = append(events, e) })
+	s.Continue(60, []Action{{TargetCharIndex: 0, Type: ActionTypeAttack}})
+	if len(events) == 0 || events[0].Type != EventSimStart || events[0].Version != EventVersion || events[0].Frame != 60 {
+		t.Errorf("expected the continued log to start with sim_start on frame 60, got %+v", events)
+	}
+}
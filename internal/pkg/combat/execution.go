@@ -0,0 +1,83 @@
+package combat
+
+import (
+	"math"
+	"math/rand"
+)
+
+//Distribution is a normal distribution of frames, clamped to [Min, Max]. A
+//zero Max means no upper bound; the zero value always samples 0
+type Distribution struct {
+	Mean   float64 `yaml:"Mean"`
+	StdDev float64 `yaml:"StdDev"`
+	Min    float64 `yaml:"Min"`
+	Max    float64 `yaml:"Max"`
+}
+
+//Sample draws a number of frames from the distribution
+func (d Distribution) Sample() int {
+	return d.sample(nil)
+}
+
+//sample draws from r, or the global source if r is nil
+func (d Distribution) sample(r *rand.Rand) int {
+	v := d.Mean + randNorm(r)*d.StdDev
+	if v < d.Min {
+		v = d.Min
+	}
+	if d.Max > 0 && v > d.Max {
+		v = d.Max
+	}
+	return int(math.Round(v))
+}
+
+//ExecutionProfile models the mistakes a human makes executing a rotation
+type ExecutionProfile struct {
+	//extra frames between every action
+	ReactionDelay Distribution `yaml:"ReactionDelay"`
+	//chance an animation cancel is dropped; the full animation plays out and the
+	//frames here are lost on top of it
+	DroppedCancel ErrorProfile `yaml:"DroppedCancel"`
+	//chance a swap is mistimed, and the frames lost when it is
+	MistimedSwap ErrorProfile `yaml:"MistimedSwap"`
+}
+
+//ErrorProfile is an occasional mistake costing some frames
+type ErrorProfile struct {
+	Chance float64      `yaml:"Chance"`
+	Frames Distribution `yaml:"Frames"`
+}
+
+//happened rolls whether the mistake is made
+func (e ErrorProfile) happened(r *rand.Rand) bool {
+	return e.Chance > 0 && randFloat(r) < e.Chance
+}
+
+//roll returns the frames lost to the mistake, 0 if it didn't happen
+func (e ErrorProfile) roll(r *rand.Rand) int {
+	if !e.happened(r) {
+		return 0
+	}
+	return e.Frames.sample(r)
+}
+
+//actionDelay returns the extra frames a human adds after an action
+func (s *Sim) actionDelay() int {
+	if s.execution == nil {
+		return 0
+	}
+	return s.execution.ReactionDelay.sample(s.rand)
+}
+
+//swapDelay returns the extra frames a human adds to a swap
+func (s *Sim) swapDelay() int {
+	if s.execution == nil {
+		return 0
+	}
+	delay := s.execution.ReactionDelay.sample(s.rand)
+	if d := s.execution.MistimedSwap.roll(s.rand); d > 0 {
+		s.print(true, "swap mistimed, lost %v frames", d)
+		delay += d
+	}
+	return delay
+}
@@ -0,0 +1,58 @@
+package combat
+
+import "testing"
+
+func TestDistribution(t *testing.T) {
+	var zero Distribution
+	for i := 0; i < 100; i++ {
+		if v := zero.Sample(); v != 0 {
+			t.Fatalf("zero distribution sampled %v", v)
+		}
+	}
+	d := Distribution{Mean: 10, StdDev: 50, Min: 5, Max: 20}
+	for i := 0; i < 1000; i++ {
+		if v := d.Sample(); v < 5 || v > 20 {
+			t.Fatalf("sample %v outside [5, 20]", v)
+		}
+	}
+}
+
+func TestErrorProfile(t *testing.T) {
+	never := ErrorProfile{Chance: 0, Frames: Distribution{Mean: 30}}
+	always := ErrorProfile{Chance: 1, Frames: Distribution{Mean: 30}}
+	for i := 0; i < 100; i++ {
+		if v := never.roll(nil); v != 0 {
+			t.Fatalf("0 chance error cost %v frames", v)
+		}
+		if v := always.roll(nil); v != 30 {
+			t.Fatalf("certain error expected 30 frames, got %v", v)
+		}
+	}
+}
+
+func TestMonteCarlo(t *testing.T) {
+	//runs only differ from each other if each gets its own seed
+	p := testProfile("Test Search")
+	p.Seed = 1
+	p.Execution = &ExecutionProfile{
+		ReactionDelay: Distribution{Mean: 5, StdDev: 2, Min: 0},
+	}
+	list := []Action{{TargetCharIndex: 0, Type: ActionTypeAttack}}
+	r, err := MonteCarlo(p, 10, list, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Runs != 5 || r.Min > r.Mean || r.Max < r.Mean {
+		t.Errorf("unexpected summary %+v", r)
+	}
+	if r.StdDev <= 0 {
+		t.Errorf("expected runs to differ, got %+v", r)
+	}
+	again, err := MonteCarlo(p, 10, list, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != r {
+		t.Errorf("same seed gave %+v, then %+v", r, again)
+	}
+}
@@ -0,0 +1,73 @@
+package combat
+
+//fieldTarget determines who benefits from a field effect
+type fieldTarget string
+
+//field targets
+const (
+	//only the active character standing in the field benefits
+	FieldTargetActive fieldTarget = "active"
+	//every party member in the area benefits; the sim assumes the whole party
+	//(including off field summons) is inside the area
+	FieldTargetArea fieldTarget = "area"
+)
+
+//FieldEffect is a buff placed on the field by a character, i.e. bennett ult or
+//ganyu ult. It's picked up by Character.Snapshot for whoever is eligible at the
+//time of the snapshot
+type FieldEffect struct {
+	Key      string               //unique key; adding a field with the same key replaces it
+	Owner    string               //name of the character that created the field
+	Duration int                  //frames the field lasts
+	Target   fieldTarget          //who can benefit from the field
+	Stats    map[StatType]float64 //stat modifiers
+	Mod      func(snap *snapshot) //optional damage modifier
+	//optional; the field only applies to a character while it returns true.
+	//it's called from inside a snapshot so it can't snapshot the character
+	Cond func(c *Character) bool
+
+	expiry int //frame the field expires on
+}
+
+//AddFieldEffect adds a field effect, replacing any existing field with the same key
+func (s *Sim) AddFieldEffect(f FieldEffect) {
+	f.expiry = s.Frame + f.Duration
+	s.fields[f.Key] = f
+	s.print(false, "field effect %v added by %v, duration: %v", f.Key, f.Owner, f.Duration)
+	s.addEffect(func(snap *snapshot) bool {
+		if f.Target == FieldTargetActive && snap.CharName != s.Characters[s.Active].Profile.Name {
+			return false
+		}
+		if f.Cond != nil && !f.Cond(snap.char) {
+			return false
+		}
+		for k, v := range f.Stats {
+			snap.Stats[k] += v
+		}
+		if f.Mod != nil {
+			f.Mod(snap)
+		}
+		return false
+	}, f.Key, fieldEffectHook)
+}
+
+//RemoveFieldEffect removes the field effect with the given key
+func (s *Sim) RemoveFieldEffect(key string) {
+	delete(s.fields, key)
+	delete(s.effects[fieldEffectHook], key)
+}
+
+//HasFieldEffect returns true if the field effect with the given key is active
+func (s *Sim) HasFieldEffect(key string) bool {
+	_, ok := s.fields[key]
+	return ok
+}
+
+func (s *Sim) tickFields() {
+	for k, f := range s.fields {
+		if s.Frame > f.expiry {
+			s.print(true, "field effect %v expired", k)
+			s.RemoveFieldEffect(k)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package combat
+
+import "testing"
+
+func TestFieldEffect(t *testing.T) {
+	s, err := New(testProfile("Test Pyro", "Test Cryo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddFieldEffect(FieldEffect{
+		Key:      "test-active",
+		Owner:    "Test Pyro",
+		Duration: 60,
+		Target:   FieldTargetActive,
+		Stats:    map[StatType]float64{ATK: 100},
+	})
+	s.AddFieldEffect(FieldEffect{
+		Key:      "test-area",
+		Owner:    "Test Pyro",
+		Duration: 120,
+		Target:   FieldTargetArea,
+		Stats:    map[StatType]float64{CryoP: 0.2},
+	})
+
+	active := s.Characters[0].Snapshot(Pyro)
+	if active.Stats[ATK] != 100 || active.Stats[CryoP] != 0.2 {
+		t.Errorf("active character expected both field effects, got %v", active.Stats)
+	}
+	off := s.Characters[1].Snapshot(Cryo)
+	if off.Stats[ATK] != 0 || off.Stats[CryoP] != 0.2 {
+		t.Errorf("off field character expected only area field effect, got %v", off.Stats)
+	}
+
+	//swap; buff follows the active character
+	s.Active = 1
+	if d := s.Characters[1].Snapshot(Cryo); d.Stats[ATK] != 100 {
+		t.Errorf("new active character expected active field effect, got %v", d.Stats)
+	}
+
+	for s.Frame = 0; s.Frame <= 61; s.Frame++ {
+		s.tickFields()
+	}
+	if s.HasFieldEffect("test-active") {
+		t.Errorf("expected test-active to expire")
+	}
+	if d := s.Characters[1].Snapshot(Cryo); d.Stats[ATK] != 0 || d.Stats[CryoP] != 0.2 {
+		t.Errorf("expected only area field effect after expiry, got %v", d.Stats)
+	}
+}
+
+func TestFieldEffectCond(t *testing.T) {
+	s, err := New(testProfile("Test Pyro", "Test Cryo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	on := false
+	s.AddFieldEffect(FieldEffect{
+		Key:      "test-cond",
+		Owner:    "Test Pyro",
+		Duration: 60,
+		Target:   FieldTargetArea,
+		Stats:    map[StatType]float64{ATK: 100},
+		Cond:     func(c *Character) bool { return on && c == s.Characters[0] },
+	})
+	if d := s.Characters[0].Snapshot(Pyro); d.Stats[ATK] != 0 {
+		t.Errorf("expected no bonus while the condition is false, got %v", d.Stats[ATK])
+	}
+	on = true
+	if d := s.Characters[0].Snapshot(Pyro); d.Stats[ATK] != 100 {
+		t.Errorf("expected the bonus once the condition holds, got %v", d.Stats[ATK])
+	}
+	if d := s.Characters[1].Snapshot(Cryo); d.Stats[ATK] != 0 {
+		t.Errorf("expected the condition to be checked per character, got %v", d.Stats[ATK])
+	}
+}
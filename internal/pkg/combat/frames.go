@@ -0,0 +1,91 @@
+package combat
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+//FrameTable holds the frames an action takes before the next action can start,
+//keyed by action then by the next action. Actions into a swap use ActionTypeSwap.
+//Entries are for the plain ability; variants the ability reports with
+//Character.Variant play out in full
+type FrameTable map[ActionType]map[ActionType]int
+
+//LoadFrameTable parses a yaml frame table
+func LoadFrameTable(data []byte) (FrameTable, error) {
+	var t FrameTable
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	for a, next := range t {
+		for n, f := range next {
+			if f < 0 {
+				return nil, fmt.Errorf("negative frames for %v -> %v: %v", a, n, f)
+			}
+		}
+	}
+	return t, nil
+}
+
+//MustLoadFrameTable is like LoadFrameTable but panics on error; meant for
+//frame tables embedded in character packages
+func MustLoadFrameTable(data []byte) FrameTable {
+	t, err := LoadFrameTable(data)
+	if err != nil {
+		panic("combat: invalid frame table: " + err.Error())
+	}
+	return t
+}
+
+//Frames returns the frames from action a into next, and whether the table has
+//an entry for it
+func (t FrameTable) Frames(a, next ActionType) (int, bool) {
+	f, ok := t[a][next]
+	return f, ok
+}
+
+//merge returns a copy of the table with the entries of o on top
+func (t FrameTable) merge(o FrameTable) FrameTable {
+	r := make(FrameTable)
+	for _, src := range []FrameTable{t, o} {
+		for a, next := range src {
+			if _, ok := r[a]; !ok {
+				r[a] = make(map[ActionType]int)
+			}
+			for n, f := range next {
+				r[a][n] = f
+			}
+		}
+	}
+	return r
+}
+
+//transitionFrames returns how long the action just executed blocks the next
+//action. full is the frames returned by the ability; the character's frame
+//table can shorten it (i.e. dash or jump cancels) depending on what comes next,
+//but never to before the last hit the ability reported with Hitmark
+func (s *Sim) transitionFrames(c *Character, a Action, next Action, full int) int {
+	n := next.Type
+	if next.TargetCharIndex != s.Active {
+		n = ActionTypeSwap
+	}
+	f, ok := c.Frames.Frames(a.Type, n)
+	if !ok || c.variant {
+		return full
+	}
+	//hits still to land keep the action going until the last of them
+	if f < c.hitmark {
+		f = c.hitmark
+		if f > full {
+			f = full
+		}
+	}
+	if f < full && s.execution != nil && s.execution.DroppedCancel.happened(s.rand) {
+		d := s.execution.DroppedCancel.Frames.sample(s.rand)
+		s.print(true, "%v -> %v cancel dropped, lost %v frames", a.Type, n, full-f+d)
+		return full + d
+	}
+	s.print(true, "%v -> %v takes %v frames (full animation %v)", a.Type, n, f, full)
+	return f
+}
@@ -0,0 +1,102 @@
+package combat
+
+import "testing"
+
+func TestLoadFrameTable(t *testing.T) {
+	ft, err := LoadFrameTable([]byte("attack:\n  dash: 20\n  swap: 25\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, ok := ft.Frames(ActionTypeAttack, ActionTypeDash); !ok || f != 20 {
+		t.Errorf("attack -> dash expected 20, got %v %v", f, ok)
+	}
+	if _, ok := ft.Frames(ActionTypeSkill, ActionTypeDash); ok {
+		t.Errorf("skill -> dash should not be in the table")
+	}
+	if _, err := LoadFrameTable([]byte("attack:\n  dash: -1\n")); err == nil {
+		t.Errorf("expected error on negative frames")
+	}
+
+	m := ft.merge(FrameTable{ActionTypeAttack: {ActionTypeDash: 15}})
+	if f, _ := m.Frames(ActionTypeAttack, ActionTypeDash); f != 15 {
+		t.Errorf("merged attack -> dash expected 15, got %v", f)
+	}
+	if f, _ := m.Frames(ActionTypeAttack, ActionTypeSwap); f != 25 {
+		t.Errorf("merged attack -> swap expected 25, got %v", f)
+	}
+	if f, _ := ft.Frames(ActionTypeAttack, ActionTypeDash); f != 20 {
+		t.Errorf("merge modified the original table")
+	}
+}
+
+func TestTransitionFrames(t *testing.T) {
+	p := testProfile("Test Pyro", "Test Cryo")
+	p.Characters[0].Frames = FrameTable{ActionTypeJump: {ActionTypeDash: 30, ActionTypeSwap: 40}}
+	s, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Characters[0]
+	jump := Action{TargetCharIndex: 0, Type: ActionTypeJump}
+
+	if f := s.transitionFrames(c, jump, Action{TargetCharIndex: 0, Type: ActionTypeDash}, 100); f != 30 {
+		t.Errorf("jump -> dash expected 30, got %v", f)
+	}
+	if f := s.transitionFrames(c, jump, Action{TargetCharIndex: 1, Type: ActionTypeDash}, 100); f != 40 {
+		t.Errorf("jump -> swap expected 40, got %v", f)
+	}
+	if f := s.transitionFrames(c, jump, jump, 100); f != 100 {
+		t.Errorf("jump -> jump expected full animation 100, got %v", f)
+	}
+	//a variant the ability reports plays out in full, whatever its params
+	c.Variant()
+	if f := s.transitionFrames(c, jump, Action{TargetCharIndex: 0, Type: ActionTypeDash}, 100); f != 100 {
+		t.Errorf("variant jump -> dash expected full animation 100, got %v", f)
+	}
+	c.variant = false
+
+	//the cancel waits for the last hit of the action, but no longer than the
+	//full animation
+	c.Hitmark(45)
+	if f := s.transitionFrames(c, jump, Action{TargetCharIndex: 0, Type: ActionTypeDash}, 100); f != 45 {
+		t.Errorf("jump with a hit at 45 -> dash expected 45, got %v", f)
+	}
+	c.Hitmark(120)
+	if f := s.transitionFrames(c, jump, Action{TargetCharIndex: 0, Type: ActionTypeDash}, 100); f != 100 {
+		t.Errorf("jump with a hit after the animation -> dash expected 100, got %v", f)
+	}
+	c.hitmark = 0
+
+	s.execution = &ExecutionProfile{DroppedCancel: ErrorProfile{Chance: 1, Frames: Distribution{Mean: 5}}}
+	if f := s.transitionFrames(c, jump, Action{TargetCharIndex: 0, Type: ActionTypeDash}, 100); f != 105 {
+		t.Errorf("dropped cancel expected 105, got %v", f)
+	}
+}
+
+func TestAbilityVariant(t *testing.T) {
+	s, err := New(testProfile("Test Pyro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Characters[0]
+	c.Frames = FrameTable{ActionTypeSkill: {ActionTypeDash: 10}}
+	//holds unless told to tap, so the variant is picked without any params
+	c.Skill = func(s *Sim, p ActionParams) int {
+		if !p.Has("tap") {
+			c.Variant()
+		}
+		return 100
+	}
+	dash := Action{TargetCharIndex: 0, Type: ActionTypeDash}
+
+	hold := Action{TargetCharIndex: 0, Type: ActionTypeSkill}
+	full := s.handleAction(0, hold)
+	if f := s.transitionFrames(c, hold, dash, full); f != 100 {
+		t.Errorf("default hold -> dash expected full animation 100, got %v", f)
+	}
+	tap := Action{TargetCharIndex: 0, Type: ActionTypeSkill, Params: ActionParams{"tap": true}}
+	full = s.handleAction(0, tap)
+	if f := s.transitionFrames(c, tap, dash, full); f != 10 {
+		t.Errorf("tap -> dash expected 10, got %v", f)
+	}
+}
@@ -0,0 +1,179 @@
+package combat
+
+import "sort"
+
+//EnemyAttack is an attack the enemy repeats on a schedule against the active
+//character
+type EnemyAttack struct {
+	Name      string  `yaml:"Name"`
+	Element   eleType `yaml:"Element"`
+	Damage    float64 `yaml:"Damage"`    //raw damage before the character's def
+	Start     int     `yaml:"Start"`     //frame of the first hit
+	Interval  int     `yaml:"Interval"`  //frames between hits; 0 only hits once
+	Interrupt int     `yaml:"Interrupt"` //frames the rotation is delayed if the hit isn't shielded
+}
+
+//hits returns true if the attack lands on the given frame
+func (a EnemyAttack) hits(f int) bool {
+	if f < a.Start {
+		return false
+	}
+	if a.Interval <= 0 {
+		return f == a.Start
+	}
+	return (f-a.Start)%a.Interval == 0
+}
+
+//Def returns the snapshot's total def
+func (s *snapshot) Def() float64 {
+	return s.BaseDef*(1+s.Stats[DEFP]) + s.Stats[DEF]
+}
+
+//Dead returns true if the character's hp has dropped to 0
+func (c *Character) Dead() bool {
+	return c.HP <= 0
+}
+
+func (s *Sim) tickEnemyAttacks() {
+	for _, a := range s.Target.attacks {
+		if a.hits(s.Frame) {
+			s.damageActive(a)
+		}
+	}
+}
+
+//damageActive applies an enemy attack to the active character; shields
+//absorb the damage first, and an unshielded hit interrupts the rotation
+func (s *Sim) damageActive(a EnemyAttack) {
+	c := s.Characters[s.Active]
+	if c.Dead() {
+		return
+	}
+	d := c.Snapshot(Physical)
+	//character def; enemy level is used in place of the attacker's level
+	def := d.Def()
+	dmg := a.Damage * (1 - def/(def+5*float64(s.Target.Level)+500))
+
+	shielded := s.IsShielded()
+	absorbed := s.absorb(a.Element, dmg, d.Stats[ShieldStrength])
+	dmg -= absorbed
+	c.HP -= dmg
+	s.damageTaken += dmg
+	s.print(false, "%v hit %v for %.0f damage (%.0f absorbed by shields), hp: %.0f", a.Name, c.Profile.Name, dmg, absorbed, c.HP)
+
+	if c.Dead() {
+		c.HP = 0
+		s.print(false, "%v died", c.Profile.Name)
+		s.interruptAction()
+		s.forceSwap()
+		return
+	}
+	if !shielded && a.Interrupt > 0 {
+		s.print(true, "%v interrupted for %v frames", c.Profile.Name, a.Interrupt)
+		s.interrupt += a.Interrupt
+		s.interruptAction()
+	}
+}
+
+//interruptAction cancels the hits of the action in progress that haven't
+//landed yet
+func (s *Sim) interruptAction() {
+	if s.current == nil {
+		return
+	}
+	c := s.Characters[s.Active]
+	for _, k := range c.hits {
+		if _, ok := s.actions[k]; ok {
+			s.print(true, "%v %v interrupted, cancelling %v", c.Profile.Name, s.current.Action, k)
+			delete(s.actions, k)
+		}
+	}
+	c.hits = nil
+}
+
+//forceSwap swaps a dead active character out for the first living one,
+//ending whatever it was doing; nothing happens if the whole party is dead
+func (s *Sim) forceSwap() {
+	for i, c := range s.Characters {
+		if c.Dead() {
+			continue
+		}
+		if s.current != nil {
+			s.endAction(s.current)
+			s.current = nil
+		}
+		s.print(false, "%v died, swapping to char #%v", s.Characters[s.Active].Profile.Name, i)
+		s.emit(Event{Type: EventSwap, Char: c.Profile.Name, From: s.Characters[s.Active].Profile.Name})
+		s.Active = i
+		s.cooldown = 150
+		return
+	}
+}
+
+//shieldEfficiency is how much damage each point of shield hp absorbs; geo
+//shields absorb 150% against everything, other shields 250% against their
+//own element
+func shieldEfficiency(shield, attack eleType) float64 {
+	switch {
+	case shield == Geo:
+		return 1.5
+	case shield == attack:
+		return 2.5
+	}
+	return 1
+}
+
+//absorb takes damage off the shields, returning the amount absorbed. strength
+//is the active character's shield strength bonus
+func (s *Sim) absorb(e eleType, dmg float64, strength float64) float64 {
+	var keys []string
+	for k := range s.shields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	absorbed := 0.0
+	for _, k := range keys {
+		if dmg <= 0 {
+			break
+		}
+		sh := s.shields[k]
+		eff := shieldEfficiency(sh.Element, e) * (1 + strength)
+		taken := dmg
+		if sh.HP*eff < taken {
+			taken = sh.HP * eff
+		}
+		sh.HP -= taken / eff
+		dmg -= taken
+		absorbed += taken
+		if sh.HP <= 0 {
+			s.print(false, "shield %v broke", k)
+			delete(s.shields, k)
+			continue
+		}
+		s.shields[k] = sh
+	}
+	return absorbed
+}
+
+//HealCharacter heals character i; amt should already include the healer's
+//healing bonus. The character's incoming healing bonus is added here. Dead
+//characters can't be healed. Returns the hp actually restored
+func (s *Sim) HealCharacter(i int, amt float64) float64 {
+	c := s.Characters[i]
+	if c.Dead() {
+		return 0
+	}
+	d := c.Snapshot(Physical)
+	amt = amt * (1 + d.Stats[IncomingHeal])
+	max := d.MaxHP()
+	if c.HP+amt > max {
+		amt = max - c.HP
+	}
+	c.HP += amt
+	return amt
+}
+
+//DamageTaken returns the total damage the party took after shields
+func (s *Sim) DamageTaken() float64 {
+	return s.damageTaken
+}
@@ -0,0 +1,125 @@
+package combat
+
+import "testing"
+
+func TestEnemyAttackSchedule(t *testing.T) {
+	once := EnemyAttack{Start: 30}
+	every := EnemyAttack{Start: 30, Interval: 60}
+	for f, want := range map[int][2]bool{0: {false, false}, 30: {true, true}, 90: {false, true}, 100: {false, false}} {
+		if once.hits(f) != want[0] || every.hits(f) != want[1] {
+			t.Errorf("frame %v: expected %v, got %v %v", f, want, once.hits(f), every.hits(f))
+		}
+	}
+}
+
+func TestDamageActive(t *testing.T) {
+	s, err := New(testProfile("Test Pyro", "Test Cryo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Characters[0]
+	hit := EnemyAttack{Name: "slam", Element: Physical, Damage: 1000, Interrupt: 20}
+
+	//no def on test characters so the full hit lands
+	s.damageActive(hit)
+	if c.HP != 9000 || s.interrupt != 20 || s.DamageTaken() != 1000 {
+		t.Errorf("unshielded hit: expected 9000 hp and 20 frame interrupt, got %v %v", c.HP, s.interrupt)
+	}
+	s.interrupt = 0
+
+	//geo shields absorb 150%; 500 shield hp takes 750 damage
+	s.AddShield(Shield{Key: "geo", Element: Geo, HP: 500, Duration: 600})
+	s.damageActive(hit)
+	if c.HP != 8750 || s.interrupt != 0 || s.HasShield("geo") {
+		t.Errorf("geo shield: expected 8750 hp, no interrupt, broken shield, got %v %v %v", c.HP, s.interrupt, s.shields)
+	}
+
+	//shield strength scales absorption
+	c.Stats[ShieldStrength] = 1
+	s.AddShield(Shield{Key: "pyro", Element: Pyro, HP: 200, Duration: 600})
+	s.damageActive(EnemyAttack{Element: Pyro, Damage: 500})
+	if c.HP != 8750 || s.shields["pyro"].HP != 100 {
+		t.Errorf("pyro shield: expected 100 shield hp left, got %v %v", c.HP, s.shields["pyro"].HP)
+	}
+
+	s.damageActive(EnemyAttack{Damage: 100000})
+	if !c.Dead() || c.HP != 0 {
+		t.Errorf("expected character to die, hp %v", c.HP)
+	}
+}
+
+func TestHealCharacter(t *testing.T) {
+	s, err := New(testProfile("Test Pyro", "Test Cryo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Characters[1]
+	c.HP = 5000
+	c.Stats[IncomingHeal] = 0.5
+	if amt := s.HealCharacter(1, 1000); amt != 1500 || c.HP != 6500 {
+		t.Errorf("expected 1500 healed, got %v (hp %v)", amt, c.HP)
+	}
+	if amt := s.HealCharacter(1, 100000); c.HP != 10000 || amt != 3500 {
+		t.Errorf("expected heal capped at max hp, got %v (hp %v)", amt, c.HP)
+	}
+	c.HP = 0
+	if amt := s.HealCharacter(1, 1000); amt != 0 || !c.Dead() {
+		t.Errorf("dead characters can't be healed, got %v", amt)
+	}
+}
+
+func TestInterruptDelaysRotation(t *testing.T) {
+	p := testProfile("Test Pyro")
+	quiet, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Enemy.Attacks = []EnemyAttack{{Name: "swipe", Damage: 1, Start: 10, Interval: 60, Interrupt: 30}}
+	loud, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attacks [2]int
+	for i, s := range []*Sim{quiet, loud} {
+		i := i
+		s.Characters[0].Attack = func(s *Sim, p ActionParams) int {
+			attacks[i]++
+			return 60
+		}
+		s.Run(10, []Action{{TargetCharIndex: 0, Type: ActionTypeAttack}})
+	}
+	if attacks[1] >= attacks[0] {
+		t.Errorf("expected interrupts to reduce actions, got %v", attacks)
+	}
+}
+
+func TestInterruptCancelsHits(t *testing.T) {
+	cases := []struct {
+		name   string
+		attack EnemyAttack
+		hits   int
+		active int
+	}{
+		{"no attack", EnemyAttack{Start: 600}, 1, 0},
+		{"interrupt", EnemyAttack{Damage: 1, Start: 10, Interrupt: 30}, 0, 0},
+		{"death", EnemyAttack{Damage: 100000, Start: 10}, 0, 1},
+	}
+	for _, c := range cases {
+		p := testProfile("Test Pyro", "Test Cryo")
+		p.Enemy.Attacks = []EnemyAttack{c.attack}
+		s, err := New(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x := s.Characters[0]
+		hits := 0
+		x.Attack = func(s *Sim, p ActionParams) int {
+			x.AddHit(func(s *Sim) { hits++ }, 30, "test-hit")
+			return 60
+		}
+		s.Run(1, []Action{{TargetCharIndex: 0, Type: ActionTypeAttack}})
+		if hits != c.hits || s.Active != c.active {
+			t.Errorf("%v: expected %v hits with char #%v active, got %v with #%v", c.name, c.hits, c.active, hits, s.Active)
+		}
+	}
+}
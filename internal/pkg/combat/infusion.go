@@ -0,0 +1,106 @@
+package combat
+
+import "sort"
+
+//InfusionSource is where an infusion comes from; a character's own infusion
+//always wins over one applied by someone else
+type InfusionSource int
+
+//InfusionSource constants, in increasing order of precedence
+const (
+	InfusionExternal InfusionSource = iota
+	InfusionSelf
+)
+
+//Infusion converts a character's physical normal, charged and plunge attacks
+//into the given element
+type Infusion struct {
+	Key      string
+	Element  eleType
+	Source   InfusionSource
+	Priority int  //breaks ties between infusions of the same source, higher wins
+	Duration int  //in frames; 0 lasts until removed
+	OnField  bool //only applies while the character is active
+	start    int
+}
+
+//AddInfusion adds or replaces the infusion with the same key
+func (c *Character) AddInfusion(i Infusion) {
+	if c.infusions == nil {
+		c.infusions = make(map[string]Infusion)
+	}
+	if c.sim != nil {
+		i.start = c.sim.Frame
+	}
+	c.infusions[i.Key] = i
+}
+
+//RemoveInfusion removes the infusion with the given key
+func (c *Character) RemoveInfusion(key string) {
+	delete(c.infusions, key)
+}
+
+//HasInfusion returns true if the infusion with the given key hasn't expired
+func (c *Character) HasInfusion(key string) bool {
+	i, ok := c.infusions[key]
+	return ok && !c.infusionExpired(i)
+}
+
+func (c *Character) infusionExpired(i Infusion) bool {
+	return i.Duration > 0 && c.sim != nil && c.sim.Frame-i.start >= i.Duration
+}
+
+//Infusion returns the element currently infusing the character's attacks, or
+//an empty string if there is none. Self infusions win over external ones, then
+//higher priority, then the most recently applied
+func (c *Character) Infusion() eleType {
+	var active []Infusion
+	for _, i := range c.infusions {
+		if c.infusionExpired(i) {
+			continue
+		}
+		if i.OnField && c.sim != nil && c.sim.Characters[c.sim.Active] != c {
+			continue
+		}
+		active = append(active, i)
+	}
+	if len(active) == 0 {
+		return ""
+	}
+	sort.Slice(active, func(a, b int) bool {
+		x, y := active[a], active[b]
+		if x.Source != y.Source {
+			return x.Source > y.Source
+		}
+		if x.Priority != y.Priority {
+			return x.Priority > y.Priority
+		}
+		if x.start != y.start {
+			return x.start > y.start
+		}
+		return x.Key < y.Key
+	})
+	return active[0].Element
+}
+
+//tickInfusions drops expired infusions
+func (c *Character) tickInfusions() {
+	for k, i := range c.infusions {
+		if c.infusionExpired(i) {
+			delete(c.infusions, k)
+		}
+	}
+}
+
+//infusionICD returns whether an infused hit applies its aura. infused attacks
+//follow the standard icd: the first hit applies, then every 3rd hit after it,
+//and the count starts over once 2.5s have passed since the icd started
+func (c *Character) infusionICD() bool {
+	n, _ := c.Store["ICD-infusion-hits"].(int)
+	if _, ok := c.Cooldown["ICD-infusion"]; !ok {
+		n = 0
+		c.Cooldown["ICD-infusion"] = 150
+	}
+	c.Store["ICD-infusion-hits"] = (n + 1) % 3
+	return n == 0
+}
@@ -0,0 +1,122 @@
+package combat
+
+import "testing"
+
+func TestInfusionPriority(t *testing.T) {
+	s, err := New(testProfile("Test Pyro", "Test Cryo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Characters[0]
+	if e := c.Infusion(); e != "" {
+		t.Fatalf("expected no infusion, got %v", e)
+	}
+
+	c.AddInfusion(Infusion{Key: "external", Element: Pyro, Source: InfusionExternal, Priority: 10})
+	if e := c.Infusion(); e != Pyro {
+		t.Errorf("expected pyro infusion, got %v", e)
+	}
+	//self infusion wins regardless of priority
+	c.AddInfusion(Infusion{Key: "self", Element: Anemo, Source: InfusionSelf})
+	if e := c.Infusion(); e != Anemo {
+		t.Errorf("expected self anemo infusion to win, got %v", e)
+	}
+	c.RemoveInfusion("self")
+
+	//higher priority wins within the same source
+	c.AddInfusion(Infusion{Key: "low", Element: Cryo, Source: InfusionExternal, Priority: 1})
+	if e := c.Infusion(); e != Pyro {
+		t.Errorf("expected higher priority pyro infusion, got %v", e)
+	}
+	//equal priority; the most recent wins
+	s.Frame = 10
+	c.AddInfusion(Infusion{Key: "recent", Element: Electro, Source: InfusionExternal, Priority: 10})
+	if e := c.Infusion(); e != Electro {
+		t.Errorf("expected most recent electro infusion, got %v", e)
+	}
+}
+
+func TestInfusionExpiry(t *testing.T) {
+	s, err := New(testProfile("Test Pyro", "Test Cryo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Characters[1]
+	c.AddInfusion(Infusion{Key: "field", Element: Pyro, OnField: true})
+	c.AddInfusion(Infusion{Key: "timed", Element: Hydro, Priority: -1, Duration: 60})
+
+	//off field; only the timed infusion applies
+	if e := c.Infusion(); e != Hydro {
+		t.Errorf("expected hydro infusion while off field, got %v", e)
+	}
+	s.Active = 1
+	if e := c.Infusion(); e != Pyro {
+		t.Errorf("expected on field pyro infusion, got %v", e)
+	}
+	c.RemoveInfusion("field")
+	s.Frame = 60
+	if e := c.Infusion(); e != "" || c.HasInfusion("timed") {
+		t.Errorf("expected timed infusion to expire, got %v", e)
+	}
+	//looking up the infusion doesn't change anything; the tick cleans it up
+	if _, ok := c.infusions["timed"]; !ok {
+		t.Errorf("expected the expired infusion to stay until the tick")
+	}
+	c.tick(s)
+	if _, ok := c.infusions["timed"]; ok {
+		t.Errorf("expected the tick to drop the expired infusion")
+	}
+}
+
+func TestInfusionSnapshot(t *testing.T) {
+	s, err := New(testProfile("Test Pyro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Characters[0]
+	c.Stats[PyroP] = 0.5
+	c.AddInfusion(Infusion{Key: "test", Element: Pyro})
+
+	d := c.Snapshot(Physical)
+	if d.Element != Pyro || !d.ApplyAura || d.AuraGauge != 1 {
+		t.Errorf("expected infused pyro attack applying 1A aura, got %v %v %v", d.Element, d.ApplyAura, d.AuraGauge)
+	}
+	//elemental abilities aren't affected
+	if d := c.Snapshot(Cryo); d.Element != Cryo {
+		t.Errorf("expected cryo snapshot to stay cryo, got %v", d.Element)
+	}
+
+	s.ApplyDamage(d)
+	if _, ok := s.Target.auras[Pyro]; !ok {
+		t.Errorf("expected infused attack to apply pyro, got %v", s.Target.auras)
+	}
+}
+
+func TestInfusionICD(t *testing.T) {
+	s, err := New(testProfile("Test Pyro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Characters[0]
+	c.AddInfusion(Infusion{Key: "test", Element: Pyro})
+
+	//the first hit and every 3rd one after it apply pyro
+	applied := func() bool {
+		s.Target.auras = make(map[eleType]aura)
+		s.ApplyDamage(c.Snapshot(Physical))
+		_, ok := s.Target.auras[Pyro]
+		return ok
+	}
+	for i, expected := range []bool{true, false, false, true, false} {
+		if a := applied(); a != expected {
+			t.Errorf("hit %v: expected aura applied %v, got %v", i+1, expected, a)
+		}
+	}
+	//the count starts over once 2.5s have passed
+	for i := 0; i < 151; i++ {
+		c.tick(s)
+	}
+	if !applied() {
+		t.Errorf("expected the first hit after the icd timer to apply pyro")
+	}
+}
@@ -0,0 +1,84 @@
+package combat
+
+import (
+	"context"
+	"math"
+)
+
+//Summary describes the dps spread over a number of simulation runs
+type Summary struct {
+	Runs   int
+	Mean   float64
+	Min    float64
+	Max    float64
+	StdDev float64
+}
+
+//MonteCarlo runs the profile n times, each run length seconds long, and
+//summarizes the resulting dps
+func MonteCarlo(p Profile, length int, list []Action, n int) (Summary, error) {
+	results, err := MonteCarloContext(context.Background(), p, EndCondition{Seconds: float64(length)}, list, n, nil)
+	if err != nil {
+		return Summary{Runs: n}, err
+	}
+	dps := make([]float64, 0, n)
+	for _, r := range results {
+		dps = append(dps, r.DPS)
+	}
+	return Summarize(dps), nil
+}
+
+//MonteCarloContext runs the profile n times until end, returning the result of
+//every run. If the profile has a seed, run i is seeded with it plus i so that
+//runs differ from each other but the whole set can be reproduced. hook, if not
+//nil, is called with each sim before it runs. It stops early with the context's
+//error once ctx is done
+func MonteCarloContext(ctx context.Context, p Profile, end EndCondition, list []Action, n int, hook func(i int, s *Sim)) ([]Result, error) {
+	results := make([]Result, 0, n)
+	for i := 0; i < n; i++ {
+		cfg := p
+		if p.Seed != 0 {
+			cfg.Seed = p.Seed + int64(i)
+		}
+		s, err := New(cfg)
+		if err != nil {
+			return results, err
+		}
+		if hook != nil {
+			hook(i, s)
+		}
+		r, err := s.RunContext(ctx, end, list)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+//Summarize describes the spread of the dps of a number of runs
+func Summarize(dps []float64) Summary {
+	r := Summary{
+		Runs: len(dps),
+		Min:  math.MaxFloat64,
+	}
+	if len(dps) == 0 {
+		r.Min = 0
+		return r
+	}
+	var sum, sumSq float64
+	for _, v := range dps {
+		sum += v
+		sumSq += v * v
+		if v < r.Min {
+			r.Min = v
+		}
+		if v > r.Max {
+			r.Max = v
+		}
+	}
+	n := float64(len(dps))
+	r.Mean = sum / n
+	r.StdDev = math.Sqrt(math.Max(sumSq/n-r.Mean*r.Mean, 0))
+	return r
+}
@@ -0,0 +1,41 @@
+package combat
+
+//ActionParams are free-form parameters passed to an ability so characters can
+//implement variants (tap vs hold, charge levels) without new action types
+type ActionParams map[string]interface{}
+
+//Has returns true if the parameter was set
+func (p ActionParams) Has(key string) bool {
+	_, ok := p[key]
+	return ok
+}
+
+//Bool returns the parameter as a bool; non zero numbers count as true
+func (p ActionParams) Bool(key string) bool {
+	switch v := p[key].(type) {
+	case bool:
+		return v
+	case int:
+		return v != 0
+	case float64:
+		return v != 0
+	}
+	return false
+}
+
+//Int returns the parameter as an int, or def if it's not set or not a number;
+//true counts as 1
+func (p ActionParams) Int(key string, def int) int {
+	switch v := p[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	}
+	return def
+}
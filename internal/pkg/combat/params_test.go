@@ -0,0 +1,20 @@
+package combat
+
+import "testing"
+
+func TestActionParams(t *testing.T) {
+	var none ActionParams
+	if none.Has("hold") || none.Bool("hold") || none.Int("charge_level", 2) != 2 {
+		t.Errorf("nil params should be unset")
+	}
+	p := ActionParams{"hold": true, "charge_level": 1, "n": 2.0, "name": "x"}
+	if !p.Has("hold") || !p.Bool("hold") || p.Int("hold", 0) != 1 {
+		t.Errorf("hold: true should be set, true, and 1")
+	}
+	if p.Int("charge_level", 2) != 1 || p.Int("n", 0) != 2 {
+		t.Errorf("unexpected ints %v %v", p.Int("charge_level", 2), p.Int("n", 0))
+	}
+	if p.Bool("name") || p.Int("name", 5) != 5 {
+		t.Errorf("non numeric params should fall back to defaults")
+	}
+}
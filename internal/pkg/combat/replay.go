@@ -0,0 +1,172 @@
+package combat
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+//ReplayVersion is bumped whenever the replay format changes
+const ReplayVersion = 1
+
+//Replay is the exact sequence of actions and swaps a run executed, with the
+//seed it ran with, so it can be re-executed against the same or another build
+type Replay struct {
+	Version int            `yaml:"Version"`
+	Seed    int64          `yaml:"Seed"`
+	Frames  int            `yaml:"Frames"`
+	Damage  float64        `yaml:"Damage"`  //total damage of the recorded run
+	Profile Profile        `yaml:"Profile"` //build the run was recorded with
+	Actions []ReplayAction `yaml:"Actions"`
+}
+
+//ReplayAction is one action or swap and the frame it started on
+type ReplayAction struct {
+	Frame  int          `yaml:"Frame"`
+	Char   string       `yaml:"Char"`
+	Action ActionType   `yaml:"Action"`
+	Params ActionParams `yaml:"Params,omitempty"`
+	//the character wasn't ready (i.e. skill on cooldown) but the rotation used
+	//the action anyway
+	NotReady bool `yaml:"NotReady,omitempty"`
+}
+
+//LoadReplay parses a replay saved as yaml
+func LoadReplay(data []byte) (Replay, error) {
+	var r Replay
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return r, err
+	}
+	if r.Version != ReplayVersion {
+		return r, fmt.Errorf("unsupported replay version: %v", r.Version)
+	}
+	return r, nil
+}
+
+//Divergence is a point where a replayed run stops matching its recording
+type Divergence struct {
+	Frame  int //frame of the replayed run
+	Index  int //index of the recorded action
+	Char   string
+	Action ActionType
+	Reason string
+}
+
+func (d Divergence) String() string {
+	return fmt.Sprintf("[%v] #%v %v %v: %v", PrintFrames(d.Frame), d.Index, d.Char, d.Action, d.Reason)
+}
+
+//record adds an action or swap to the recording
+func (s *Sim) record(char int, a Action) {
+	c := s.Characters[char]
+	ready, _ := c.Ready(a.Type)
+	s.recording = append(s.recording, ReplayAction{
+		Frame:    s.Frame,
+		Char:     c.Profile.Name,
+		Action:   a.Type,
+		Params:   a.Params,
+		NotReady: !ready,
+	})
+}
+
+//Recording returns everything executed so far as a replay
+func (s *Sim) Recording() Replay {
+	actions := make([]ReplayAction, len(s.recording))
+	copy(actions, s.recording)
+	return Replay{
+		Version: ReplayVersion,
+		Seed:    s.seed,
+		Frames:  s.Frame,
+		Damage:  s.Target.damage,
+		Profile: s.stats.Profile,
+		Actions: actions,
+	}
+}
+
+//Replay re-executes a recording, each action no earlier than the frame it was
+//recorded on. Actions that were ready when recorded but can't be executed now
+//(i.e. on cooldown, not enough energy) are skipped; every such point and every
+//time the replay falls behind the recording is reported
+func (s *Sim) Replay(r Replay) (Result, []Divergence) {
+	s.reseed(r.Seed)
+	p := &playback{
+		actions: r.Actions,
+		chars:   make(map[string]int),
+	}
+	//duplicate names resolve to the first character
+	for i := len(s.Characters) - 1; i >= 0; i-- {
+		p.chars[s.Characters[i].Profile.Name] = i
+	}
+	s.emit(Event{Type: EventSimStart, Version: EventVersion})
+	res := s.run(r.Frames, p)
+	return res, p.divergences
+}
+
+//playback feeds a recording to the sim
+type playback struct {
+	actions     []ReplayAction
+	chars       map[string]int
+	i           int
+	late        int //frames the replay is behind the recording
+	divergences []Divergence
+}
+
+func (p *playback) diverge(s *Sim, a ReplayAction, reason string, args ...interface{}) {
+	d := Divergence{
+		Frame:  s.Frame,
+		Index:  p.i,
+		Char:   a.Char,
+		Action: a.Action,
+		Reason: fmt.Sprintf(reason, args...),
+	}
+	s.print(false, "replay diverged: %v", d)
+	p.divergences = append(p.divergences, d)
+}
+
+func (p *playback) next(s *Sim) (Action, bool) {
+	for ; p.i < len(p.actions); p.i++ {
+		a := p.actions[p.i]
+		if s.Frame < a.Frame {
+			return Action{}, false
+		}
+		i, ok := p.chars[a.Char]
+		if !ok {
+			p.diverge(s, a, "character not in team")
+			continue
+		}
+		c := s.Characters[i]
+		if c.Dead() {
+			p.diverge(s, a, "character is dead")
+			continue
+		}
+		if ok, reason := c.Ready(a.Action); !ok && !a.NotReady {
+			p.diverge(s, a, reason)
+			continue
+		}
+		//only report when falling further behind; everything after a late
+		//action tends to be late by as much
+		late := s.Frame - a.Frame
+		if late > p.late {
+			p.diverge(s, a, "started %v frames late", late)
+		}
+		p.late = late
+		return Action{TargetCharIndex: i, Type: a.Action, Params: a.Params}, true
+	}
+	return Action{}, false
+}
+
+func (p *playback) advance() {
+	p.i++
+}
+
+func (p *playback) peek() (Action, bool) {
+	if p.i >= len(p.actions) {
+		return Action{}, false
+	}
+	a := p.actions[p.i]
+	i, ok := p.chars[a.Char]
+	if !ok {
+		return Action{}, false
+	}
+	return Action{TargetCharIndex: i, Type: a.Action, Params: a.Params}, true
+}
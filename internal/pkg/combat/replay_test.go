@@ -0,0 +1,161 @@
+package combat
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+//replaySim sets up a team whose skill and burst respect cooldowns and energy;
+//the skill cooldown is cd frames
+func replaySim(t *testing.T, cd int) *Sim {
+	p := testProfile("Test Pyro", "Test Cryo")
+	p.Seed = 42
+	s, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range s.Characters {
+		c := c
+		c.Stats[CR] = 0.5
+		c.Stats[CD] = 1
+		c.MaxEnergy = 40
+		hit := func(abil string) {
+			d := c.Snapshot(Physical)
+			d.Abil = abil
+			d.Mult = 1
+			s.ApplyDamage(d)
+		}
+		c.Attack = func(s *Sim, p ActionParams) int {
+			hit("Normal")
+			return 30
+		}
+		c.Skill = func(s *Sim, p ActionParams) int {
+			hit("Skill")
+			c.Cooldown["skill-cd"] = cd
+			c.Energy += 20
+			return 30
+		}
+		c.Burst = func(s *Sim, p ActionParams) int {
+			hit("Burst")
+			c.Energy = 0
+			c.Cooldown["burst-cd"] = 600
+			return 60
+		}
+	}
+	return s
+}
+
+var replayRotation = []Action{
+	{TargetCharIndex: 0, Type: ActionTypeSkill},
+	{TargetCharIndex: 0, Type: ActionTypeAttack, Params: ActionParams{"hits": 2}},
+	{TargetCharIndex: 1, Type: ActionTypeSkill},
+	{TargetCharIndex: 1, Type: ActionTypeAttack},
+	{TargetCharIndex: 1, Type: ActionTypeAttack},
+	{TargetCharIndex: 1, Type: ActionTypeAttack},
+	{TargetCharIndex: 1, Type: ActionTypeAttack},
+	{TargetCharIndex: 1, Type: ActionTypeAttack},
+	{TargetCharIndex: 1, Type: ActionTypeSkill},
+	{TargetCharIndex: 1, Type: ActionTypeBurst},
+}
+
+func TestSeedDeterminism(t *testing.T) {
+	a := replaySim(t, 60).Run(20, replayRotation)
+	b := replaySim(t, 60).Run(20, replayRotation)
+	if a.Damage != b.Damage || a.Characters["Test Pyro"].Crits != b.Characters["Test Pyro"].Crits {
+		t.Errorf("expected the same seed to give the same run, got %v and %v", a.Damage, b.Damage)
+	}
+
+	p := testProfile("Test Pyro")
+	p.Seed = 42
+	s, _ := New(p)
+	if s.Seed() != 42 {
+		t.Errorf("expected seed 42, got %v", s.Seed())
+	}
+	p.Seed = 0
+	s, _ = New(p)
+	if s.Seed() == 0 {
+		t.Errorf("expected a random seed to be picked")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	s := replaySim(t, 60)
+	orig := s.Run(20, replayRotation)
+	rec := s.Recording()
+	if rec.Seed != 42 || rec.Frames != 1200 || rec.Damage != orig.Damage {
+		t.Fatalf("unexpected recording %v %v %v", rec.Seed, rec.Frames, rec.Damage)
+	}
+	if a := rec.Actions[0]; a.Frame != 0 || a.Char != "Test Pyro" || a.Action != ActionTypeSkill || a.NotReady {
+		t.Errorf("unexpected first action %+v", rec.Actions[0])
+	}
+	swaps := 0
+	for _, a := range rec.Actions {
+		if a.Action == ActionTypeSwap {
+			swaps++
+		}
+	}
+	if swaps == 0 {
+		t.Errorf("expected swaps to be recorded")
+	}
+
+	//round trip through a file
+	out, err := yaml.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err = LoadReplay(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Actions[1].Params.Int("hits", 0) != 2 {
+		t.Errorf("expected params to survive a round trip, got %v", rec.Actions[1].Params)
+	}
+
+	//same build; identical run
+	r, div := replaySim(t, 60).Replay(rec)
+	if len(div) != 0 {
+		t.Errorf("expected no divergence, got %v", div)
+	}
+	if r.Damage != orig.Damage || len(r.Actions) != len(orig.Actions) {
+		t.Errorf("expected the same run, got %v (%v actions), want %v (%v actions)", r.Damage, len(r.Actions), orig.Damage, len(orig.Actions))
+	}
+
+	//a longer skill cooldown means the second cryo skill is skipped, so the
+	//burst is short on energy too
+	_, div = replaySim(t, 300).Replay(rec)
+	if len(div) < 2 {
+		t.Fatalf("expected cooldown and energy divergences, got %v", div)
+	}
+	if div[0].Char != "Test Cryo" || div[0].Action != ActionTypeSkill || !strings.Contains(div[0].Reason, "cooldown") {
+		t.Errorf("expected cryo skill on cooldown first, got %v", div[0])
+	}
+	if div[1].Action != ActionTypeBurst || !strings.Contains(div[1].Reason, "energy") {
+		t.Errorf("expected cryo burst short on energy next, got %v", div[1])
+	}
+}
+
+func TestReplayLate(t *testing.T) {
+	rec := Replay{
+		Version: ReplayVersion,
+		Seed:    1,
+		Frames:  300,
+		Actions: []ReplayAction{
+			{Frame: 0, Char: "Test Pyro", Action: ActionTypeAttack},
+			{Frame: 10, Char: "Test Pyro", Action: ActionTypeAttack},
+			{Frame: 41, Char: "Test Pyro", Action: ActionTypeAttack},
+			{Frame: 52, Char: "Nobody", Action: ActionTypeAttack},
+		},
+	}
+	_, div := replaySim(t, 60).Replay(rec)
+	//the second attack can only start on frame 31; the third is just as late,
+	//which isn't reported again
+	if len(div) != 2 || div[0].Index != 1 || div[0].Reason != "started 21 frames late" || div[1].Reason != "character not in team" {
+		t.Errorf("unexpected divergences %v", div)
+	}
+
+	if _, err := LoadReplay([]byte("Version: 99")); err == nil {
+		t.Errorf("expected unsupported version to error")
+	}
+}
@@ -0,0 +1,85 @@
+package combat
+
+//addResonance applies elemental resonance if the team has 4 characters; a
+//resonance is active for each element shared by at least 2 characters
+func (s *Sim) addResonance() {
+	if len(s.Characters) != 4 {
+		return
+	}
+	count := make(map[eleType]int)
+	for _, c := range s.Characters {
+		//characters without an element never count towards a resonance
+		if c.Element == "" {
+			continue
+		}
+		count[c.Element]++
+	}
+
+	for e, n := range count {
+		if n < 2 {
+			continue
+		}
+		s.print(false, "adding %v resonance", e)
+		switch e {
+		case Pyro:
+			//fervent flames: atk +25%
+			for _, c := range s.Characters {
+				c.Mods["Pyro Resonance"] = map[StatType]float64{ATKP: 0.25}
+			}
+		case Hydro:
+			//soothing water: hp +25%
+			for _, c := range s.Characters {
+				c.Mods["Hydro Resonance"] = map[StatType]float64{HPP: 0.25}
+			}
+		case Cryo:
+			//shattering ice: cr +15% against frozen or cryo affected enemies
+			for _, c := range s.Characters {
+				s.addConditionalMod(c.Profile.Name, "Cryo Resonance", s.Target.cryoAffected)
+			}
+			s.addEffect(func(snap *snapshot) bool {
+				if s.Target.cryoAffected() {
+					s.log.Debugf("applying cryo resonance on cryo/frozen target")
+					snap.Stats[CR] += .15
+				}
+				return false
+			}, "cryo resonance", preDamageHook)
+		case Geo:
+			//enduring rock: dmg +15% while shielded; geo dmg reduces geo res by
+			//20% for 15s
+			for _, c := range s.Characters {
+				s.addConditionalMod(c.Profile.Name, "Geo Resonance", s.IsShielded)
+			}
+			s.addEffect(func(snap *snapshot) bool {
+				if s.IsShielded() {
+					snap.DmgBonus += .15
+				}
+				if snap.Element == Geo && s.Target.HasStatus("geo resonance") {
+					snap.ResMod -= .2
+				}
+				return false
+			}, "geo resonance", preDamageHook)
+			s.addEffect(func(snap *snapshot) bool {
+				if snap.Element == Geo {
+					s.Target.AddStatus("geo resonance", 15*60)
+				}
+				return false
+			}, "geo resonance", postDamageHook)
+		case Electro:
+			//high voltage: superconduct, overload, and electro-charged generate
+			//an electro particle; 5s cd
+			s.addEffect(func(snap *snapshot) bool {
+				switch snap.Reaction {
+				case Superconduct, Overload, ElectroCharged:
+				default:
+					return false
+				}
+				if s.Target.HasStatus("electro resonance") {
+					return false
+				}
+				s.Target.AddStatus("electro resonance", 5*60)
+				s.GenerateParticles(Electro, 1)
+				return false
+			}, "electro resonance", postAuraAppHook)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package combat
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func init() {
+	for name, e := range map[string]eleType{
+		"Test Pyro":    Pyro,
+		"Test Cryo":    Cryo,
+		"Test Hydro":   Hydro,
+		"Test Geo":     Geo,
+		"Test Electro": Electro,
+		//second characters of the same element for resonance
+		"Test Pyro 2":    Pyro,
+		"Test Cryo 2":    Cryo,
+		"Test Hydro 2":   Hydro,
+		"Test Electro 2": Electro,
+	} {
+		e := e
+		RegisterCharFunc(name, func(s *Sim, log *zap.SugaredLogger) *Character {
+			c := &Character{}
+			c.Element = e
+			return c
+		})
+	}
+}
+
+func testProfile(names ...string) Profile {
+	var p Profile
+	p.Enemy.Level = 88
+	p.Enemy.Resist = 0.1
+	p.LogLevel = "error"
+	for _, n := range names {
+		p.Characters = append(p.Characters, CharacterProfile{
+			Name:       n,
+			Level:      90,
+			BaseHP:     10000,
+			BaseAtk:    300,
+			WeaponName: "Prototype Crescent",
+			TalentLevel: map[ActionType]int64{
+				ActionTypeAttack: 6,
+				ActionTypeSkill:  6,
+				ActionTypeBurst:  6,
+			},
+		})
+	}
+	return p
+}
+
+func TestResonance(t *testing.T) {
+	s, err := New(testProfile("Test Pyro", "Test Pyro 2", "Test Hydro", "Test Hydro 2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range s.Characters {
+		if c.Mods["Pyro Resonance"][ATKP] != 0.25 {
+			t.Errorf("%v missing pyro resonance: %v", c.Profile.Name, c.Mods)
+		}
+		if c.HP != 12500 {
+			t.Errorf("%v expected hydro resonance hp 12500, got %v", c.Profile.Name, c.HP)
+		}
+	}
+
+	//resonance needs a full team
+	s, err = New(testProfile("Test Pyro", "Test Pyro 2", "Test Hydro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range s.Characters {
+		if _, ok := c.Mods["Pyro Resonance"]; ok {
+			t.Errorf("%v should not have pyro resonance with 3 characters", c.Profile.Name)
+		}
+	}
+}
+
+func TestCryoResonance(t *testing.T) {
+	s, err := New(testProfile("Test Cryo", "Test Cryo 2", "Test Geo", "Test Electro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := s.Characters[0].Snapshot(Cryo)
+	cr := d.Stats[CR]
+	s.Target.auras[Cryo] = aura{gauge: 1, unit: "A", duration: 100}
+	for _, f := range s.effects[preDamageHook] {
+		f(&d)
+	}
+	if d.Stats[CR]-cr != 0.15 {
+		t.Errorf("expected cryo resonance to add 15%% cr against cryo target, got %v", d.Stats[CR]-cr)
+	}
+}
+
+func TestElectroResonance(t *testing.T) {
+	s, err := New(testProfile("Test Electro", "Test Electro 2", "Test Cryo", "Test Pyro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range s.Characters {
+		c.Energy = 0
+		c.MaxEnergy = 100
+	}
+
+	d := s.Characters[2].Snapshot(Cryo)
+	d.ApplyAura = true
+	d.AuraGauge = 1
+	d.AuraUnit = "A"
+	s.ApplyDamage(d)
+
+	d = s.Characters[0].Snapshot(Electro)
+	d.ApplyAura = true
+	d.AuraGauge = 1
+	d.AuraUnit = "A"
+	s.ApplyDamage(d)
+
+	if _, ok := s.Target.auras[Cryo]; ok {
+		t.Errorf("expected superconduct to consume cryo aura")
+	}
+	//active electro character gets a full particle
+	if s.Characters[0].Energy != 3 {
+		t.Errorf("expected 3 energy from electro resonance particle, got %v", s.Characters[0].Energy)
+	}
+	//off field, different element
+	if s.Characters[2].Energy != 0.6 {
+		t.Errorf("expected 0.6 energy from electro resonance particle, got %v", s.Characters[2].Energy)
+	}
+}
@@ -0,0 +1,193 @@
+package combat
+
+//Result is the outcome of a sim run
+type Result struct {
+	Profile     Profile //config that produced the result
+	Frames      int
+	Damage      float64
+	DPS         float64
+	DamageTaken float64 //damage the party took after shields
+
+	Characters map[string]*CharacterResult
+	Elements   map[eleType]*DamageStats
+	Reactions  map[reactionType]*DamageStats //damage of hits that triggered each reaction
+
+	DamagePerSecond []float64 //damage dealt during each second of the run
+	Actions         []ActionRecord
+	Uptime          Uptimes
+
+	//full passes through the action list; each is credited with the damage
+	//it caused, wherever that landed
+	Cycles         []CycleResult
+	DamagePerCycle float64 //average over full passes
+	CycleDPS       float64 //dps over full passes only, leaving out a partial last one
+
+	caused []float64 //damage caused by each pass, by pass
+}
+
+//ActionRecord is an action a character took and the frames it occupied
+type ActionRecord struct {
+	Char   string
+	Action ActionType
+	Interval
+}
+
+//CycleResult is one full pass through the action list; Damage is everything
+//the pass's actions dealt, including hits that landed after it ended
+type CycleResult struct {
+	Interval
+	Damage float64
+	DPS    float64
+}
+
+//CharacterResult is one character's share of the damage
+type CharacterResult struct {
+	DamageStats
+	Abilities map[string]*DamageStats
+}
+
+//DamageStats sums up a group of hits
+type DamageStats struct {
+	Damage     float64
+	Hits       int
+	Crits      int
+	AverageHit float64
+}
+
+func (d *DamageStats) add(damage float64, crit bool) {
+	d.Damage += damage
+	d.Hits++
+	if crit {
+		d.Crits++
+	}
+	d.AverageHit = d.Damage / float64(d.Hits)
+}
+
+//credit adds damage caused by the given pass
+func (r *Result) credit(pass int, damage float64) {
+	if damage == 0 {
+		return
+	}
+	for len(r.caused) <= pass {
+		r.caused = append(r.caused, 0)
+	}
+	r.caused[pass] += damage
+}
+
+func newResult(p Profile) *Result {
+	return &Result{
+		Profile:    p,
+		Characters: make(map[string]*CharacterResult),
+		Elements:   make(map[eleType]*DamageStats),
+		Reactions:  make(map[reactionType]*DamageStats),
+		Uptime:     newUptimes(),
+	}
+}
+
+//add records one hit landing on frame f
+func (r *Result) add(ds snapshot, damage float64, crit bool, f int) {
+	sec := f / FramesPerSecond
+	for len(r.DamagePerSecond) <= sec {
+		r.DamagePerSecond = append(r.DamagePerSecond, 0)
+	}
+	r.DamagePerSecond[sec] += damage
+
+	c, ok := r.Characters[ds.CharName]
+	if !ok {
+		c = &CharacterResult{Abilities: make(map[string]*DamageStats)}
+		r.Characters[ds.CharName] = c
+	}
+	c.add(damage, crit)
+	if _, ok := c.Abilities[ds.Abil]; !ok {
+		c.Abilities[ds.Abil] = &DamageStats{}
+	}
+	c.Abilities[ds.Abil].add(damage, crit)
+	if _, ok := r.Elements[ds.Element]; !ok {
+		r.Elements[ds.Element] = &DamageStats{}
+	}
+	r.Elements[ds.Element].add(damage, crit)
+	if ds.Reaction != "" {
+		if _, ok := r.Reactions[ds.Reaction]; !ok {
+			r.Reactions[ds.Reaction] = &DamageStats{}
+		}
+		r.Reactions[ds.Reaction].add(damage, crit)
+	}
+}
+
+//result finalizes the stats collected so far; the sim may keep running so it
+//works on a copy
+func (s *Sim) result() Result {
+	r := *s.stats.clone()
+	//the action still playing out ends with the run
+	if e := s.current; e != nil {
+		r.Actions = append(r.Actions, ActionRecord{
+			Char:     e.Char,
+			Action:   e.Action,
+			Interval: Interval{Start: e.Frame, End: s.Frame},
+		})
+	}
+	r.Frames = s.Frame
+	r.Damage = s.Target.damage
+	if s.Frame > 0 {
+		r.DPS = r.Damage * FramesPerSecond / float64(s.Frame)
+	}
+	r.DamageTaken = s.damageTaken
+	if n := len(r.Cycles); n > 0 {
+		var damage float64
+		var frames int
+		for i := range r.Cycles {
+			c := &r.Cycles[i]
+			if i < len(r.caused) {
+				c.Damage = r.caused[i]
+			}
+			if f := c.End - c.Start; f > 0 {
+				c.DPS = c.Damage * FramesPerSecond / float64(f)
+			}
+			damage += c.Damage
+			frames += c.End - c.Start
+		}
+		r.DamagePerCycle = damage / float64(n)
+		if frames > 0 {
+			r.CycleDPS = damage * FramesPerSecond / float64(frames)
+		}
+	}
+	//pad out seconds without any damage at the end of the run
+	for len(r.DamagePerSecond) < (s.Frame+FramesPerSecond-1)/FramesPerSecond {
+		r.DamagePerSecond = append(r.DamagePerSecond, 0)
+	}
+	r.Uptime.fractions(s.Frame)
+	return r
+}
+
+//clone deep copies the result
+func (r *Result) clone() *Result {
+	c := *r
+	c.Characters = make(map[string]*CharacterResult, len(r.Characters))
+	for k, v := range r.Characters {
+		x := &CharacterResult{
+			DamageStats: v.DamageStats,
+			Abilities:   make(map[string]*DamageStats, len(v.Abilities)),
+		}
+		for abil, d := range v.Abilities {
+			d := *d
+			x.Abilities[abil] = &d
+		}
+		c.Characters[k] = x
+	}
+	c.Elements = make(map[eleType]*DamageStats, len(r.Elements))
+	for k, v := range r.Elements {
+		d := *v
+		c.Elements[k] = &d
+	}
+	c.Reactions = make(map[reactionType]*DamageStats, len(r.Reactions))
+	for k, v := range r.Reactions {
+		d := *v
+		c.Reactions[k] = &d
+	}
+	c.DamagePerSecond = append([]float64(nil), r.DamagePerSecond...)
+	c.Actions = append([]ActionRecord(nil), r.Actions...)
+	c.Cycles = append([]CycleResult(nil), r.Cycles...)
+	c.caused = append([]float64(nil), r.caused...)
+	c.Uptime = r.Uptime.clone()
+	return &c
+}
@@ -0,0 +1,77 @@
+package combat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResult(t *testing.T) {
+	p := testProfile("Test Pyro", "Test Cryo")
+	p.DamageMode = DamageModeCrit
+	s, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hit := func(c *Character, abil string, e eleType) func(s *Sim, p ActionParams) int {
+		return func(s *Sim, p ActionParams) int {
+			d := c.Snapshot(e)
+			d.Abil = abil
+			d.Mult = 1
+			d.ApplyAura = true
+			d.AuraGauge = 1
+			d.AuraUnit = "A"
+			s.ApplyDamage(d)
+			return 60
+		}
+	}
+	s.Characters[0].Attack = hit(s.Characters[0], "Normal", Pyro)
+	s.Characters[1].Skill = hit(s.Characters[1], "Skill", Cryo)
+
+	r := s.Run(10, []Action{
+		{TargetCharIndex: 0, Type: ActionTypeAttack},
+		{TargetCharIndex: 1, Type: ActionTypeSkill},
+	})
+	if r.Frames != 600 || math.Abs(r.DPS-r.Damage/10) > 0.0001 {
+		t.Errorf("expected 600 frames and dps over 10s, got %v %v", r.Frames, r.DPS)
+	}
+	if r.Profile.Characters[0].Name != "Test Pyro" {
+		t.Errorf("expected result to carry the profile")
+	}
+	pyro, cryo := r.Characters["Test Pyro"], r.Characters["Test Cryo"]
+	if pyro == nil || cryo == nil {
+		t.Fatalf("expected both characters in result, got %v", r.Characters)
+	}
+	if math.Abs(pyro.Damage+cryo.Damage-r.Damage) > 0.0001 {
+		t.Errorf("character damage %v + %v doesn't add up to %v", pyro.Damage, cryo.Damage, r.Damage)
+	}
+	normal := pyro.Abilities["Normal"]
+	if normal == nil || normal.Hits != pyro.Hits || normal.Crits != normal.Hits {
+		t.Errorf("expected every normal to crit, got %+v", normal)
+	}
+	if math.Abs(normal.AverageHit*float64(normal.Hits)-normal.Damage) > 0.0001 {
+		t.Errorf("unexpected average hit %+v", normal)
+	}
+	if r.Elements[Pyro].Hits != pyro.Hits || r.Elements[Cryo].Hits != cryo.Hits {
+		t.Errorf("unexpected element breakdown %v", r.Elements)
+	}
+	sum := 0.0
+	for _, d := range r.DamagePerSecond {
+		sum += d
+	}
+	if len(r.DamagePerSecond) != 10 || math.Abs(sum-r.Damage) > 0.0001 {
+		t.Errorf("expected 10 seconds of damage adding up to %v, got %v", r.Damage, r.DamagePerSecond)
+	}
+	if len(r.Actions) != pyro.Hits+cryo.Hits || r.Actions[0] != (ActionRecord{Char: "Test Pyro", Action: ActionTypeAttack, Interval: Interval{0, 61}}) {
+		t.Errorf("unexpected actions %v", r.Actions)
+	}
+	//every cryo hit lands on the pyro applied before it and melts
+	if m := r.Reactions[Melt]; m == nil || m.Hits != cryo.Hits {
+		t.Errorf("expected every cryo hit to melt, got %+v", m)
+	}
+}
+
+func TestDuplicateCharacters(t *testing.T) {
+	if _, err := New(testProfile("Test Pyro", "Test Pyro")); err == nil {
+		t.Errorf("expected error for duplicate characters")
+	}
+}
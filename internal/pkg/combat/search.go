@@ -0,0 +1,283 @@
+package combat
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+//SearchOptions constrains a rotation search
+type SearchOptions struct {
+	Frames int //length of the fight
+	//actions each character may use, by character name; the TargetCharIndex of
+	//each action is ignored. Characters not listed may use every action they
+	//implement
+	Actions map[string][]Action
+	//every character with a burst has to use it at least once
+	RequireBursts bool
+	Beam          int //sequences kept after each step; defaults to 8
+	Top           int //sequences returned; defaults to 5
+	//frames the sim keeps running after a partial sequence to count damage
+	//still on its way (i.e. a burst still ticking) when ranking it; defaults
+	//to 600
+	Settle int
+}
+
+//Candidate is a rotation found by the search
+type Candidate struct {
+	Actions []Action
+	Damage  float64
+	DPS     float64
+}
+
+//node is a partial rotation and the sim state at the end of it, on a frame
+//where the sim is free for the next action
+type node struct {
+	cp      *Checkpoint
+	actions []Action
+	score   float64
+	bursts  float64 //progress towards every character bursting, see burstProgress
+}
+
+//Search looks for the rotations dealing the most damage over the fight using a
+//beam search. Damage is always averaged over crits so scores are stable, and
+//an action is only tried when the character is ready to use it. Each action is
+//assumed to play out in full before the next one starts
+func Search(p Profile, o SearchOptions) ([]Candidate, error) {
+	if o.Frames <= 0 {
+		return nil, errors.New("fight length must be positive")
+	}
+	if o.Beam <= 0 {
+		o.Beam = 8
+	}
+	if o.Top <= 0 {
+		o.Top = 5
+	}
+	if o.Settle <= 0 {
+		o.Settle = 600
+	}
+	p.DamageMode = DamageModeAverage
+	if p.Seed == 0 {
+		p.Seed = 1
+	}
+	s, err := New(p)
+	if err != nil {
+		return nil, err
+	}
+	choices, err := s.searchChoices(o.Actions)
+	if err != nil {
+		return nil, err
+	}
+
+	beam := []node{{cp: s.Checkpoint()}}
+	var done []node
+	for len(beam) > 0 {
+		var next []node
+		for _, n := range beam {
+			for _, a := range choices {
+				s.Restore(n.cp)
+				c := s.Characters[a.TargetCharIndex]
+				if c.Dead() {
+					continue
+				}
+				if ok, _ := c.Ready(a.Type); !ok {
+					continue
+				}
+				child := node{actions: append(append([]Action(nil), n.actions...), a)}
+				s.searchStep(a, o.Frames)
+				if s.Frame >= o.Frames {
+					child.score = s.Target.damage
+					done = append(done, child)
+					continue
+				}
+				child.cp = s.Checkpoint()
+				child.bursts = s.burstProgress(child.actions)
+				child.score = s.searchScore(o.Frames, o.Settle)
+				next = append(next, child)
+			}
+		}
+		sort.SliceStable(next, func(i, j int) bool { return next[i].score > next[j].score })
+		if len(next) > o.Beam {
+			beam = next[:o.Beam]
+			if o.RequireBursts {
+				beam = append(beam, reserveBursts(next[o.Beam:], next[:o.Beam], o.Beam)...)
+			}
+		} else {
+			beam = next
+		}
+	}
+
+	if o.RequireBursts {
+		var keep []node
+		for _, n := range done {
+			if s.usesBursts(n.actions) {
+				keep = append(keep, n)
+			}
+		}
+		done = keep
+	}
+	if len(done) == 0 {
+		return nil, errors.New("no rotation satisfies the constraints")
+	}
+	sort.SliceStable(done, func(i, j int) bool { return done[i].score > done[j].score })
+	var r []Candidate
+	for _, n := range done {
+		if len(r) == o.Top {
+			break
+		}
+		r = append(r, Candidate{
+			Actions: n.actions,
+			Damage:  n.score,
+			DPS:     n.score * 60 / float64(o.Frames),
+		})
+	}
+	return r, nil
+}
+
+//searchChoices lists every action the search may try
+func (s *Sim) searchChoices(allowed map[string][]Action) ([]Action, error) {
+	index := make(map[string]int)
+	for i, c := range s.Characters {
+		index[c.Profile.Name] = i
+	}
+	for name := range allowed {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("invalid character: %v", name)
+		}
+	}
+	var r []Action
+	for i, c := range s.Characters {
+		list, ok := allowed[c.Profile.Name]
+		if !ok {
+			for _, t := range []ActionType{ActionTypeAttack, ActionTypeChargedAttack, ActionTypePlungeAttack, ActionTypeSkill, ActionTypeBurst} {
+				list = append(list, Action{Type: t})
+			}
+		}
+		for _, a := range list {
+			if c.ability(a.Type) == nil {
+				if ok {
+					return nil, fmt.Errorf("%v does not implement %v", c.Profile.Name, a.Type)
+				}
+				continue
+			}
+			a.TargetCharIndex = i
+			r = append(r, a)
+		}
+	}
+	if len(r) == 0 {
+		return nil, errors.New("no actions to search")
+	}
+	return r, nil
+}
+
+//searchStep executes a, then runs on until the sim is free for another action
+//or the fight is over
+func (s *Sim) searchStep(a Action, frames int) {
+	rot := &sequence{list: []Action{a}}
+	for s.Frame < frames {
+		s.step(rot)
+		s.Frame++
+		if rot.i > 0 && s.cooldown == 0 {
+			return
+		}
+	}
+}
+
+//searchScore is the damage per frame up to settle frames from now, counting
+//damage landing in that time as long as nothing else is done
+func (s *Sim) searchScore(frames, settle int) float64 {
+	end := s.Frame + settle
+	if end > frames {
+		end = frames
+	}
+	rot := &sequence{}
+	for s.Frame < end {
+		s.step(rot)
+		s.Frame++
+	}
+	return s.Target.damage / float64(end)
+}
+
+//burstProgress is how far a rotation has got towards every character with a
+//burst using it; a burst used counts 2 and one still owed counts the fraction
+//of the energy the character has for it, so a burst ready to go is half way
+func (s *Sim) burstProgress(list []Action) float64 {
+	used := make(map[int]bool)
+	for _, a := range list {
+		if a.Type == ActionTypeBurst {
+			used[a.TargetCharIndex] = true
+		}
+	}
+	r := 0.0
+	for i, c := range s.Characters {
+		switch {
+		case c.Burst == nil:
+		case used[i]:
+			r += 2
+		case c.MaxEnergy <= 0:
+			r++
+		default:
+			r += math.Min(c.Energy/c.MaxEnergy, 1)
+		}
+	}
+	return r
+}
+
+//reserveBursts picks up to n of the nodes left out of the beam that are
+//further towards everyone bursting than any node in it, so rotations that
+//build energy first aren't crowded out by ones dealing more damage for now but
+//never getting to burst
+func reserveBursts(rest, beam []node, n int) []node {
+	best := 0.0
+	for _, b := range beam {
+		best = math.Max(best, b.bursts)
+	}
+	rest = append([]node(nil), rest...)
+	sort.SliceStable(rest, func(i, j int) bool { return rest[i].bursts > rest[j].bursts })
+	var r []node
+	for _, b := range rest {
+		if len(r) == n || b.bursts <= best {
+			break
+		}
+		r = append(r, b)
+	}
+	return r
+}
+
+//usesBursts checks every character with a burst bursts at least once
+func (s *Sim) usesBursts(list []Action) bool {
+	used := make(map[int]bool)
+	for _, a := range list {
+		if a.Type == ActionTypeBurst {
+			used[a.TargetCharIndex] = true
+		}
+	}
+	for i, c := range s.Characters {
+		if c.Burst != nil && !used[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//sequence runs a list of actions once, then does nothing
+type sequence struct {
+	list []Action
+	i    int
+}
+
+func (q *sequence) next(s *Sim) (Action, bool) {
+	if q.i >= len(q.list) {
+		return Action{}, false
+	}
+	return q.list[q.i], true
+}
+
+func (q *sequence) advance() {
+	q.i++
+}
+
+func (q *sequence) peek() (Action, bool) {
+	return q.next(nil)
+}
@@ -0,0 +1,175 @@
+package combat
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+//searchMisuse counts skills and bursts the search tried while not ready
+var searchMisuse int
+
+func init() {
+	//skill gives energy and has a cooldown, the burst needs that energy and
+	//hits far harder than anything else
+	RegisterCharFunc("Test Search", func(s *Sim, log *zap.SugaredLogger) *Character {
+		c := &Character{}
+		c.Element = Pyro
+		c.MaxEnergy = 40
+		hit := func(abil string, mult float64) {
+			d := c.Snapshot(Pyro)
+			d.Abil = abil
+			d.Mult = mult
+			s.ApplyDamage(d)
+		}
+		c.Attack = func(s *Sim, p ActionParams) int {
+			hit("Normal", 1)
+			return 30
+		}
+		c.Skill = func(s *Sim, p ActionParams) int {
+			if ok, _ := c.Ready(ActionTypeSkill); !ok {
+				searchMisuse++
+			}
+			hit("Skill", 2)
+			c.Cooldown["skill-cd"] = 300
+			c.Energy += 20
+			return 30
+		}
+		c.Burst = func(s *Sim, p ActionParams) int {
+			if ok, _ := c.Ready(ActionTypeBurst); !ok {
+				searchMisuse++
+			}
+			hit("Burst", 20)
+			c.Energy = 0
+			c.Cooldown["burst-cd"] = 600
+			return 60
+		}
+		return c
+	})
+	//the burst needs the energy of a skill that deals no damage, and barely
+	//hits at all itself
+	RegisterCharFunc("Test Late Burst", func(s *Sim, log *zap.SugaredLogger) *Character {
+		c := &Character{}
+		c.Element = Pyro
+		c.MaxEnergy = 40
+		hit := func(mult float64) {
+			d := c.Snapshot(Pyro)
+			d.Mult = mult
+			s.ApplyDamage(d)
+		}
+		c.Attack = func(s *Sim, p ActionParams) int {
+			hit(1)
+			return 30
+		}
+		c.ChargeAttack = func(s *Sim, p ActionParams) int {
+			hit(0.95)
+			return 30
+		}
+		c.Skill = func(s *Sim, p ActionParams) int {
+			c.Energy = 40
+			return 30
+		}
+		c.Burst = func(s *Sim, p ActionParams) int {
+			hit(0.1)
+			c.Energy = 0
+			c.Cooldown["burst-cd"] = 600
+			return 120
+		}
+		return c
+	})
+}
+
+func TestSearch(t *testing.T) {
+	p := testProfile("Test Search", "Test Pyro")
+	searchMisuse = 0
+	r, err := Search(p, SearchOptions{Frames: 900, Beam: 4, Top: 3, RequireBursts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if searchMisuse > 0 {
+		t.Errorf("expected only ready actions to be tried, got %v misuses", searchMisuse)
+	}
+	if len(r) != 3 {
+		t.Fatalf("expected 3 candidates, got %v", len(r))
+	}
+	for i, c := range r {
+		if i > 0 && c.Damage > r[i-1].Damage {
+			t.Errorf("expected candidates sorted by damage, got %v after %v", c.Damage, r[i-1].Damage)
+		}
+		if c.DPS != c.Damage*60/900 {
+			t.Errorf("expected dps over the fight, got %v for %v damage", c.DPS, c.Damage)
+		}
+		bursts := 0
+		for _, a := range c.Actions {
+			if a.TargetCharIndex == 0 && a.Type == ActionTypeBurst {
+				bursts++
+			}
+			if a.TargetCharIndex != 0 {
+				t.Errorf("expected only the character with abilities to act, got %v", a.TargetCharIndex)
+			}
+		}
+		if bursts == 0 {
+			t.Errorf("expected every candidate to burst, got %v", c.Actions)
+		}
+	}
+
+	//rerunning the best rotation gives the same damage
+	p.DamageMode = DamageModeAverage
+	p.Seed = 1
+	s, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := s.Continue(900, r[0].Actions)
+	if res.Damage != r[0].Damage {
+		t.Errorf("expected the best rotation to deal %v when run, got %v", r[0].Damage, res.Damage)
+	}
+
+	//restricting actions
+	r, err = Search(p, SearchOptions{
+		Frames:  600,
+		Actions: map[string][]Action{"Test Search": {{Type: ActionTypeAttack}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range r[0].Actions {
+		if a.Type != ActionTypeAttack {
+			t.Errorf("expected only attacks, got %v", a.Type)
+		}
+	}
+
+	if _, err := Search(p, SearchOptions{Frames: 600, Actions: map[string][]Action{"Nobody": nil}}); err == nil {
+		t.Errorf("expected an unknown character to fail")
+	}
+	if _, err := Search(p, SearchOptions{Frames: 600, Actions: map[string][]Action{"Test Pyro": {{Type: ActionTypeSkill}}}}); err == nil {
+		t.Errorf("expected an unimplemented action to fail")
+	}
+}
+
+func TestSearchRequireBursts(t *testing.T) {
+	//rotations that never skill or burst always score higher along the way,
+	//so the constraint has to be kept during the search rather than after it
+	p := testProfile("Test Late Burst")
+	r, err := Search(p, SearchOptions{Frames: 600, Beam: 2, RequireBursts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range r {
+		skilled, burst := false, false
+		for _, a := range c.Actions {
+			switch a.Type {
+			case ActionTypeSkill:
+				skilled = true
+			case ActionTypeBurst:
+				burst = true
+				if !skilled {
+					t.Errorf("expected a skill before the burst, got %v", c.Actions)
+				}
+			}
+		}
+		if !burst {
+			t.Errorf("expected every candidate to burst, got %v", c.Actions)
+		}
+	}
+}
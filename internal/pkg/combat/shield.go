@@ -0,0 +1,39 @@
+package combat
+
+//Shield describes a shield protecting the active character
+type Shield struct {
+	Key      string  //unique key; adding a shield with the same key replaces it
+	Element  eleType //element of the shield
+	HP       float64 //damage absorption remaining
+	Duration int     //frames until the shield expires
+}
+
+//AddShield adds a shield to the active character, replacing any existing
+//shield with the same key
+func (s *Sim) AddShield(sh Shield) {
+	s.print(false, "shield %v added, hp: %.0f, duration: %v", sh.Key, sh.HP, sh.Duration)
+	s.shields[sh.Key] = sh
+}
+
+//HasShield returns true if the shield with the given key is still up
+func (s *Sim) HasShield(key string) bool {
+	_, ok := s.shields[key]
+	return ok
+}
+
+//IsShielded returns true if the active character is protected by any shield
+func (s *Sim) IsShielded() bool {
+	return len(s.shields) > 0
+}
+
+func (s *Sim) tickShields() {
+	for k, v := range s.shields {
+		if v.Duration == 0 {
+			s.print(true, "shield %v expired", k)
+			delete(s.shields, k)
+			continue
+		}
+		v.Duration--
+		s.shields[k] = v
+	}
+}
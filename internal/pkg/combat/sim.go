@@ -9,8 +9,18 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-type AbilFunc func(s *Sim) int
-type ActionFunc func(s *Sim) bool
+type AbilFunc func(s *Sim, p ActionParams) int
+
+//ActionFunc is called every frame until it returns true; tick is the number of
+//times it has been called before
+type ActionFunc func(s *Sim, tick int) bool
+
+//task is a scheduled action and how many times it has run
+type task struct {
+	f     ActionFunc
+	tick  int
+	cause int //pass through the action list that scheduled it
+}
 
 type effectType string
 
@@ -22,7 +32,7 @@ const (
 	preActionHook   effectType = "PRE_ACTION"
 	actionHook      effectType = "ACTION"
 	postActionHook  effectType = "POST_ACTION"
-	fieldEffectHook effectType = "FIELD_EFFECt"
+	fieldEffectHook effectType = "FIELD_EFFECT"
 )
 
 type effectFunc func(s *snapshot) bool
@@ -36,10 +46,44 @@ type Sim struct {
 
 	log *zap.SugaredLogger
 
-	//per tick hooks
-	actions map[string]ActionFunc
+	//per tick hooks; run in the order they were added so the random source is
+	//always drawn from in the same order
+	actions     map[string]task
+	actionOrder []string
 	//effects
 	effects map[effectType]map[string]effectFunc
+	//buffs applied through effects, tracked for uptime
+	conditionals []conditionalMod
+	//shields protecting the active character
+	shields map[string]Shield
+	//field effects
+	fields map[string]FieldEffect
+
+	damageMode DamageMode
+	execution  *ExecutionProfile
+
+	//frames until the next action can start
+	cooldown int
+	//action currently playing out, for the event log
+	current *Event
+	//frames the rotation is delayed by enemy hits this frame
+	interrupt int
+	//total damage the party took after shields
+	damageTaken float64
+	//pass through the action list behind whatever is running, so damage can
+	//be credited to the pass that caused it
+	cause int
+	//damage breakdown collected during the run
+	stats *Result
+	//optional event log
+	events *eventLog
+
+	//random source for crits, accuracy and execution errors
+	seed int64
+	src  *source
+	rand *rand.Rand
+	//actions and swaps executed so far, for replays
+	recording []ReplayAction
 }
 
 //New creates new sim from given profile
@@ -50,13 +94,37 @@ func New(p Profile) (*Sim, error) {
 
 	u.auras = make(map[eleType]aura)
 	u.status = make(map[string]int)
+	u.ResMod = make(map[string]float64)
 	u.Level = p.Enemy.Level
 	u.Resist = p.Enemy.Resist
+	u.HP = p.Enemy.HP
+	u.attacks = p.Enemy.Attacks
+	u.shieldBroken = -1
+	if p.Enemy.Shield != nil {
+		if p.Enemy.Shield.Gauge <= 0 && p.Enemy.Shield.HP <= 0 {
+			return nil, fmt.Errorf("enemy shield needs a gauge or hp")
+		}
+		u.shield = newEnemyShield(*p.Enemy.Shield)
+	}
 
 	s.Target = u
+	s.stats = newResult(p)
 
-	s.actions = make(map[string]ActionFunc)
+	switch p.DamageMode {
+	case "":
+		s.damageMode = DamageModeRolled
+	case DamageModeRolled, DamageModeAverage, DamageModeNonCrit, DamageModeCrit:
+		s.damageMode = p.DamageMode
+	default:
+		return nil, fmt.Errorf("invalid damage mode: %v", p.DamageMode)
+	}
+	s.execution = p.Execution
+	s.reseed(p.Seed)
+
+	s.actions = make(map[string]task)
 	s.effects = make(map[effectType]map[string]effectFunc)
+	s.shields = make(map[string]Shield)
+	s.fields = make(map[string]FieldEffect)
 
 	config := zap.NewDevelopmentConfig()
 	config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
@@ -76,12 +144,20 @@ func New(p Profile) (*Sim, error) {
 	if err != nil {
 		return nil, err
 	}
+	//each sim logs through its own logger so sims running side by side don't
+	//trample each other's log level
 	s.log = logger.Sugar()
-	zap.ReplaceGlobals(logger)
+	u.log = s.log
 
 	var chars []*Character
+	//results are keyed by character name so names have to be unique
+	names := make(map[string]bool)
 	//create the characters
 	for _, v := range p.Characters {
+		if names[v.Name] {
+			return nil, fmt.Errorf("duplicate character: %v", v.Name)
+		}
+		names[v.Name] = true
 		//initialize artifact sets
 
 		f, ok := charMap[v.Name]
@@ -89,18 +165,24 @@ func New(p Profile) (*Sim, error) {
 			return nil, fmt.Errorf("invalid character: %v", v.Name)
 		}
 
-		c := f(s, logger.Sugar())
+		c := f(s, s.log)
 		//initialize other variables/stats
 		c.Stats = make(map[StatType]float64)
 		c.Cooldown = make(map[string]int)
 		c.Store = make(map[string]interface{})
 		c.Mods = make(map[string]map[StatType]float64)
 		c.Profile = v
+		c.sim = s
+		if v.Frames != nil {
+			c.Frames = c.Frames.merge(v.Frames)
+		}
 
 		//initialize weapon
 		switch v.WeaponName {
 		case "Prototype Crescent":
 			weaponPrototypeCrescent(c, s, v.WeaponRefinement)
+		case "Amos' Bow":
+			weaponAmos(c, s, v.WeaponRefinement)
 		default:
 			return nil, fmt.Errorf("invalid weapon: %v", v.WeaponName)
 		}
@@ -152,58 +234,209 @@ func New(p Profile) (*Sim, error) {
 	}
 	s.Characters = chars
 
+	s.addResonance()
+	//hp depends on resonance
+	for _, c := range s.Characters {
+		c.HP = c.MaxHP()
+	}
+
 	return s, nil
 }
 
-//Run the sim; length in seconds
-func (s *Sim) Run(length int, list []Action) float64 {
-	var cooldown int
-	var active int //index of the currently active car
-	var i int
-	rand.Seed(time.Now().UnixNano())
-	//60fps, 60s/min, 2min
-	for s.Frame = 0; s.Frame < 60*length; s.Frame++ {
-		//tick target and each character
-		//target doesn't do anything, just takes punishment, so it won't affect cd
-		s.Target.tick(s)
-		for _, c := range s.Characters {
-			//character may affect cooldown by i.e. adding to it
-			c.tick(s)
-		}
+//reseed resets the random source; a zero seed picks one at random
+func (s *Sim) reseed(seed int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s.seed = seed
+	s.src = &source{}
+	s.src.Seed(seed)
+	s.rand = rand.New(s.src)
+}
 
-		s.handleTick()
+//Seed returns the seed of the sim's random source
+func (s *Sim) Seed() int64 {
+	return s.seed
+}
 
-		//if in cooldown, do nothing
-		if cooldown > 0 {
-			cooldown--
-			continue
-		}
+//rotation feeds actions to the sim
+type rotation interface {
+	//next returns the action to execute now, false if there's nothing to do
+	next(s *Sim) (Action, bool)
+	//advance moves past the action last returned by next
+	advance()
+	//peek returns the upcoming action without moving past it
+	peek() (Action, bool)
+}
 
-		if i >= len(list) {
-			//start over
-			i = 0
-		}
-		//otherwise only either action or swaps can trigger cooldown
-		//we figure out what the next action is to be
-		next := list[i]
-
-		//check if actor is active
-		if next.TargetCharIndex != active {
-			fmt.Printf("[%v] swapping to char #%v (current = %v)\n", s.Frame, next.TargetCharIndex, active)
-			//trigger a swap
-			cooldown = 150
-			active = next.TargetCharIndex
-			continue
+//cycle repeats a list of actions for the whole run, recording each full pass
+//through it in the sim's stats
+type cycle struct {
+	list []Action
+	i    int
+
+	limit   int //passes to run; 0 for no limit
+	passes  int
+	over    bool //limit reached
+	started bool
+	start   int //frame the current pass started on
+}
 
+func (c *cycle) next(s *Sim) (Action, bool) {
+	if len(c.list) == 0 || c.over {
+		return Action{}, false
+	}
+	if !c.started {
+		c.started = true
+		c.start = s.Frame
+	}
+	if c.i >= len(c.list) {
+		//a pass ends when the next one would start
+		c.passes++
+		s.stats.Cycles = append(s.stats.Cycles, CycleResult{
+			Interval: Interval{Start: c.start, End: s.Frame},
+		})
+		c.start = s.Frame
+		if c.limit > 0 && c.passes >= c.limit {
+			c.over = true
+			return Action{}, false
 		}
-		//move on to next action on list
-		i++
+		//start over
+		c.i = 0
+	}
+	return c.list[c.i], true
+}
+
+func (c *cycle) advance() {
+	c.i++
+}
+
+func (c *cycle) peek() (Action, bool) {
+	if len(c.list) == 0 {
+		return Action{}, false
+	}
+	return c.list[c.i%len(c.list)], true
+}
+
+//Run the sim; length in seconds
+func (s *Sim) Run(length int, list []Action) Result {
+	s.emit(Event{Type: EventSimStart, Version: EventVersion})
+	return s.run(FramesPerSecond*length, &cycle{list: list})
+}
+
+//Continue runs the sim on from where it stopped for another frames frames,
+//starting the list over from the top. Together with Checkpoint and Restore
+//this lets a fight be forked at any frame
+func (s *Sim) Continue(frames int, list []Action) Result {
+	s.emit(Event{Type: EventSimStart, Version: EventVersion})
+	return s.run(s.Frame+frames, &cycle{list: list})
+}
+
+//run the sim up to the given frame
+func (s *Sim) run(until int, rot rotation) Result {
+	for ; s.Frame < until; s.Frame++ {
+		s.step(rot)
+	}
+	return s.result()
+}
+
+//step simulates the current frame, taking the next action from rot once the
+//last one is done
+func (s *Sim) step(rot rotation) {
+	//tick target and each character
+	//target only affects cd by interrupting unshielded characters
+	s.Target.tick(s)
+	s.tickEnemyAttacks()
+	s.tickShields()
+	s.tickFields()
+	for _, c := range s.Characters {
+		//character may affect cooldown by i.e. adding to it
+		c.tick(s)
+	}
+
+	s.handleTick()
+	s.trackUptime()
+	s.trackEvents()
+
+	if s.interrupt > 0 {
+		s.cooldown += s.interrupt
+		s.interrupt = 0
+	}
 
-		cooldown = s.handleAction(active, next)
+	//if in cooldown, do nothing
+	if s.cooldown > 0 {
+		s.cooldown--
+		return
+	}
+	if s.current != nil {
+		s.endAction(s.current)
+		s.current = nil
+	}
 
+	//otherwise only either action or swaps can trigger cooldown
+	//we figure out what the next action is to be
+	next, ok := rot.next(s)
+	if !ok {
+		return
+	}
+
+	//dead characters can't act; skip whatever they were meant to do
+	if s.Characters[next.TargetCharIndex].Dead() {
+		s.print(true, "char #%v is dead, skipping %v", next.TargetCharIndex, next.Type)
+		rot.advance()
+		return
+	}
+
+	//check if actor is active
+	if next.TargetCharIndex != s.Active {
+		s.print(false, "swapping to char #%v (current = %v)", next.TargetCharIndex, s.Active)
+		s.record(next.TargetCharIndex, Action{TargetCharIndex: next.TargetCharIndex, Type: ActionTypeSwap})
+		//trigger a swap
+		s.cooldown = 150 + s.swapDelay()
+		s.emit(Event{Type: EventSwap, Char: s.Characters[next.TargetCharIndex].Profile.Name, From: s.Characters[s.Active].Profile.Name})
+		s.Active = next.TargetCharIndex
+		//an explicit swap is done once it's happened
+		if next.Type == ActionTypeSwap {
+			rot.advance()
+		}
+		return
+	}
+	//move on to next action on list
+	rot.advance()
+	if next.Type == ActionTypeSwap {
+		//already on the right character
+		return
+	}
+	s.record(s.Active, next)
+
+	s.current = &Event{Frame: s.Frame, Type: EventActionStart, Char: s.Characters[s.Active].Profile.Name, Action: next.Type, Params: next.Params}
+	s.emit(*s.current)
+	//the frame table may let the animation be cancelled into whatever comes next
+	s.cause = len(s.stats.Cycles)
+	before := s.Target.damage
+	full := s.handleAction(s.Active, next)
+	s.stats.credit(s.cause, s.Target.damage-before)
+	s.cooldown = full
+	//with nothing coming up the animation plays out in full
+	if after, ok := rot.peek(); ok {
+		s.cooldown = s.transitionFrames(s.Characters[s.Active], next, after, full)
 	}
+	s.cooldown += s.actionDelay()
+	//log what the action changed on the frame it happened
+	s.trackEvents()
+}
 
-	return s.Target.damage
+//endAction records the action that just finished playing out
+func (s *Sim) endAction(e *Event) {
+	s.stats.Actions = append(s.stats.Actions, ActionRecord{
+		Char:     e.Char,
+		Action:   e.Action,
+		Interval: Interval{Start: e.Frame, End: s.Frame},
+	})
+	e.Type = EventActionEnd
+	e.Params = nil
+	e.Frames = s.Frame - e.Frame
+	s.emit(*e)
 }
 
 func (s *Sim) addEffect(f effectFunc, key string, hook effectType) {
@@ -214,17 +447,47 @@ func (s *Sim) addEffect(f effectFunc, key string, hook effectType) {
 }
 
 func (s *Sim) AddAction(f ActionFunc, key string) {
-	s.actions[key] = f
+	if _, ok := s.actions[key]; !ok {
+		s.actionOrder = append(s.actionOrder, key)
+	}
+	s.actions[key] = task{f: f, cause: s.cause}
 }
 
 //handleTick
 func (s *Sim) handleTick() {
-	for k, f := range s.actions {
-		if f(s) {
-			print(s.Frame, true, "action %v expired", k)
+	//actions added during the tick only run from the next one
+	expired := false
+	cause := s.cause
+	for _, k := range s.actionOrder {
+		t, ok := s.actions[k]
+		if !ok {
+			continue
+		}
+		//count the tick first; the action may replace itself
+		s.actions[k] = task{f: t.f, tick: t.tick + 1, cause: t.cause}
+		s.cause = t.cause
+		before := s.Target.damage
+		if t.f(s, t.tick) {
+			s.print(true, "action %v expired", k)
 			delete(s.actions, k)
+			expired = true
 		}
+		s.stats.credit(t.cause, s.Target.damage-before)
 	}
+	s.cause = cause
+	if !expired {
+		return
+	}
+	//drop expired keys; a key re-added after expiring may be listed twice
+	seen := make(map[string]bool)
+	order := s.actionOrder[:0]
+	for _, k := range s.actionOrder {
+		if _, ok := s.actions[k]; ok && !seen[k] {
+			seen[k] = true
+			order = append(order, k)
+		}
+	}
+	s.actionOrder = order
 }
 
 //handleAction executes the next action, returns the cooldown
@@ -232,38 +495,51 @@ func (s *Sim) handleAction(active int, a Action) int {
 	//if active see what ability we want to use
 	c := s.Characters[active]
 
+	var f AbilFunc
 	switch a.Type {
 	case ActionTypeDash:
-		print(s.Frame, false, "dashing")
+		s.print(false, "dashing")
 		return 100
 	case ActionTypeJump:
-		print(s.Frame, false, "dashing")
-		fmt.Printf("[%v] jumping\n", s.Frame)
+		s.print(false, "jumping")
 		return 100
 	case ActionTypeAttack:
-		print(s.Frame, false, "%v executing attack", c.Profile.Name)
-		return c.Attack(s)
+		s.print(false, "%v executing attack", c.Profile.Name)
+		f = c.Attack
 	case ActionTypeChargedAttack:
-		print(s.Frame, false, "%v executing charged attack", c.Profile.Name)
-		return c.ChargeAttack(s)
+		s.print(false, "%v executing charged attack", c.Profile.Name)
+		f = c.ChargeAttack
+	case ActionTypePlungeAttack:
+		s.print(false, "%v executing plunge attack", c.Profile.Name)
+		f = c.PlungeAttack
 	case ActionTypeBurst:
-		print(s.Frame, false, "%v executing burst", c.Profile.Name)
-		return c.Burst(s)
+		s.print(false, "%v executing burst", c.Profile.Name)
+		f = c.Burst
 	case ActionTypeSkill:
-		print(s.Frame, false, "%v executing skill", c.Profile.Name)
-		return c.Skill(s)
+		s.print(false, "%v executing skill", c.Profile.Name)
+		f = c.Skill
 	default:
 		//do nothing
-		print(s.Frame, false, "no action specified: %v. Doing nothing", a.Type)
+		s.print(false, "no action specified: %v. Doing nothing", a.Type)
+		return 0
+	}
+
+	if f == nil {
+		s.print(false, "%v does not implement %v. Doing nothing", c.Profile.Name, a.Type)
+		return 0
 	}
 
-	return 0
+	c.hitmark = 0
+	c.variant = false
+	c.hits = nil
+	return f(s, a.Params)
 }
 
 //Action describe one action to execute
 type Action struct {
 	TargetCharIndex int
 	Type            ActionType
+	Params          ActionParams //optional, selects ability variants i.e. hold: true
 }
 
 type Profile struct {
@@ -272,17 +548,48 @@ type Profile struct {
 	Characters []CharacterProfile `yaml:"Characters"`
 	Rotation   []RotationItem     `yaml:"Rotation"`
 	LogLevel   string             `yaml:"LogLevel"`
+	DamageMode DamageMode         `yaml:"DamageMode"` //rolled (default), average, noncrit, or crit
+	Execution  *ExecutionProfile  `yaml:"Execution"`  //optional human error model
+	Seed       int64              `yaml:"Seed"`       //seed for the random source; random if 0
 }
 
 //EnemyProfile ...
 type EnemyProfile struct {
-	Level  int64   `yaml:"Level"`
-	Resist float64 `yaml:"Resist"` //this needs to be a map later on
+	Level   int64         `yaml:"Level"`
+	Resist  float64       `yaml:"Resist"` //this needs to be a map later on
+	HP      float64       `yaml:"HP"`     //optional; 0 if the enemy can't die
+	Attacks []EnemyAttack `yaml:"Attacks"`
+	//optional elemental shield
+	Shield *EnemyShieldProfile `yaml:"Shield"`
 }
 
 //RotationItem ...
 type RotationItem struct {
-	CharacterName string     `yaml:"CharacterName"`
-	Action        ActionType `yaml:"Action"`
-	Condition     string     //to be implemented
+	CharacterName string       `yaml:"CharacterName"`
+	Action        ActionType   `yaml:"Action"`
+	Params        ActionParams `yaml:"Params"`
+	Condition     string       //to be implemented
+}
+
+//Actions turns the profile's rotation into actions, matching each item to a
+//character by name
+func (p Profile) Actions() ([]Action, error) {
+	index := make(map[string]int)
+	for i, c := range p.Characters {
+		index[c.Name] = i
+	}
+	var r []Action
+	for i, v := range p.Rotation {
+		c, ok := index[v.CharacterName]
+		if !ok {
+			return nil, fmt.Errorf("rotation item %v: invalid character: %v", i+1, v.CharacterName)
+		}
+		switch v.Action {
+		case ActionTypeSwap, ActionTypeDash, ActionTypeJump, ActionTypeAttack, ActionTypeSkill, ActionTypeBurst, ActionTypeChargedAttack, ActionTypePlungeAttack:
+		default:
+			return nil, fmt.Errorf("rotation item %v: invalid action: %v", i+1, v.Action)
+		}
+		r = append(r, Action{TargetCharIndex: c, Type: v.Action, Params: v.Params})
+	}
+	return r, nil
 }
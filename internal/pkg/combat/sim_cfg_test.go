@@ -0,0 +1,46 @@
+package combat_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/srliao/gansim/internal/pkg/combat"
+	_ "github.com/srliao/gansim/internal/pkg/ganyu"
+	"gopkg.in/yaml.v2"
+)
+
+//TestSim runs the example profile; it lives outside the package so the real
+//characters it uses can be imported
+func TestSim(t *testing.T) {
+
+	var source []byte
+	var cfg combat.Profile
+	var err error
+
+	source, err = ioutil.ReadFile("./test/cfg.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = yaml.Unmarshal(source, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := combat.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions = []combat.Action{
+		{
+			TargetCharIndex: 0,
+			Type:            combat.ActionTypeChargedAttack,
+		},
+	}
+	r := s.Run(6, actions)
+	if r.Damage <= 0 {
+		t.Errorf("expected damage over the run, got %v", r.Damage)
+	}
+	if r.Frames != 6*combat.FramesPerSecond {
+		t.Errorf("expected the run to last %v frames, got %v", 6*combat.FramesPerSecond, r.Frames)
+	}
+}
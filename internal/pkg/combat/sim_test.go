@@ -1,65 +1,30 @@
 package combat
 
 import (
-	"io/ioutil"
-	"log"
+	"strings"
 	"testing"
-
-	"gopkg.in/yaml.v2"
 )
 
-func TestSim(t *testing.T) {
-
-	var source []byte
-	var cfg Profile
-	var err error
-
-	source, err = ioutil.ReadFile("./test/cfg.yaml")
-	if err != nil {
-		t.Fatal(err)
+func TestProfileActions(t *testing.T) {
+	p := testProfile("Test Pyro", "Test Cryo")
+	p.Rotation = []RotationItem{
+		{CharacterName: "Test Cryo", Action: ActionTypeSkill, Params: ActionParams{"hold": 1}},
+		{CharacterName: "Test Pyro", Action: ActionTypeChargedAttack},
 	}
-	err = yaml.Unmarshal(source, &cfg)
+	a, err := p.Actions()
 	if err != nil {
 		t.Fatal(err)
 	}
+	if len(a) != 2 || a[0].TargetCharIndex != 1 || a[0].Type != ActionTypeSkill || a[0].Params["hold"] != 1 || a[1].TargetCharIndex != 0 || a[1].Type != ActionTypeChargedAttack {
+		t.Errorf("expected the rotation mapped to character indexes, got %+v", a)
+	}
 
-	s, err := New(cfg)
-	if err != nil {
-		log.Fatal(err)
+	p.Rotation = append(p.Rotation, RotationItem{CharacterName: "Nobody", Action: ActionTypeSkill})
+	if _, err := p.Actions(); err == nil || !strings.Contains(err.Error(), "Nobody") {
+		t.Errorf("expected an error naming the unknown character, got %v", err)
 	}
-	var actions = []Action{
-		// {
-		// 	TargetCharIndex: 0,
-		// 	Type:            ActionTypeBurst,
-		// },
-		{
-			TargetCharIndex: 0,
-			Type:            ActionTypeChargedAttack,
-		},
+	p.Rotation = []RotationItem{{CharacterName: "Test Pyro", Action: "cartwheel"}}
+	if _, err := p.Actions(); err == nil {
+		t.Errorf("expected an unknown action to fail")
 	}
-	s.Run(6, actions)
-
-	// s := New()
-
-	// g := newGanyu()
-	// g.BaseAtk = cfg.Profile.CharBaseAtk
-	// g.Level = cfg.Profile.CharLevel
-	// g.WeaponAtk = cfg.Profile.WeaponBaseAtk
-
-	// for _, a := range cfg.Artifacts {
-	// 	g.stats[a.MainStat.Type] += a.MainStat.Value
-	// 	for _, v := range a.Substat {
-	// 		g.stats[v.Type] += v.Value
-	// 	}
-	// }
-	// //manually add weapon mods etc..
-	// g.stats[ATKP] += 0.413
-	// g.stats[CryoP] += 0.15
-	// g.stats[CR] += 0.25
-	// g.stats[CD] += 0.884
-
-	// s.characters = append(s.characters, g)
-
-	// s.active = 0
-
 }
@@ -0,0 +1,94 @@
+package combat
+
+//snapCategory is a bit mask of stat categories
+type snapCategory int
+
+//categories of stats an ability can read live instead of snapshotting
+const (
+	//character mods, i.e. weapon procs, set bonuses, self buffs
+	DynamicMods snapCategory = 1 << iota
+	//field effects, i.e. bennett ult
+	DynamicField
+	//DynamicAll reads every category live; enemy side modifiers (resistance,
+	//defense, aura dependent effects) are always applied at hit time
+	DynamicAll = DynamicMods | DynamicField
+)
+
+//fieldBonus is what field effects added to a snapshot outside of its stats
+//through FieldEffect.Mod
+type fieldBonus struct {
+	DmgBonus   float64
+	DefMod     float64
+	ResMod     float64
+	ReactBonus float64
+	FlatDmg    float64
+}
+
+func (f fieldBonus) sub(o fieldBonus) fieldBonus {
+	return fieldBonus{
+		DmgBonus:   f.DmgBonus - o.DmgBonus,
+		DefMod:     f.DefMod - o.DefMod,
+		ResMod:     f.ResMod - o.ResMod,
+		ReactBonus: f.ReactBonus - o.ReactBonus,
+		FlatDmg:    f.FlatDmg - o.FlatDmg,
+	}
+}
+
+//bonus returns the snapshot's non stat modifiers that field effects can touch
+func (s *snapshot) bonus() fieldBonus {
+	return fieldBonus{
+		DmgBonus:   s.DmgBonus,
+		DefMod:     s.DefMod,
+		ResMod:     s.ResMod,
+		ReactBonus: s.ReactBonus,
+		FlatDmg:    s.FlatDmg,
+	}
+}
+
+//addBonus adds (or with sign -1 removes) field effect modifiers to the snapshot
+func (s *snapshot) addBonus(b fieldBonus, sign float64) {
+	s.DmgBonus += sign * b.DmgBonus
+	s.DefMod += sign * b.DefMod
+	s.ResMod += sign * b.ResMod
+	s.ReactBonus += sign * b.ReactBonus
+	s.FlatDmg += sign * b.FlatDmg
+}
+
+//refreshSnapshot replaces any stat category that the ability reads live with
+//the character's current values
+func (s *Sim) refreshSnapshot(ds *snapshot) {
+	//copy stats so that hooks don't modify stats shared with other hits from
+	//the same snapshot
+	stats := make(map[StatType]float64)
+	for k, v := range ds.Stats {
+		stats[k] = v
+	}
+	ds.Stats = stats
+
+	if ds.char == nil || ds.Frame == s.Frame || ds.Dynamic == 0 {
+		return
+	}
+	live := ds.char.Snapshot(ds.Element)
+	if ds.Dynamic&DynamicMods != 0 {
+		for k, v := range ds.mods {
+			ds.Stats[k] -= v
+		}
+		for k, v := range live.mods {
+			ds.Stats[k] += v
+		}
+		ds.mods = live.mods
+	}
+	if ds.Dynamic&DynamicField != 0 {
+		for k, v := range ds.field {
+			ds.Stats[k] -= v
+		}
+		for k, v := range live.field {
+			ds.Stats[k] += v
+		}
+		ds.field = live.field
+		ds.addBonus(ds.fieldBonus, -1)
+		ds.addBonus(live.fieldBonus, 1)
+		ds.fieldBonus = live.fieldBonus
+	}
+	s.print(true, "%v - %v refreshed stats from frame %v (dynamic: %v)", ds.CharName, ds.Abil, ds.Frame, ds.Dynamic)
+}
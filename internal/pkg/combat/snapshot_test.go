@@ -0,0 +1,81 @@
+package combat
+
+import "testing"
+
+func TestSnapshotCategories(t *testing.T) {
+	s, err := New(testProfile("Test Pyro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Characters[0]
+	c.Mods["cast"] = map[StatType]float64{ATKP: 0.1}
+
+	snapped := c.Snapshot(Pyro)
+	dynamic := c.Snapshot(Pyro)
+	dynamic.Dynamic = DynamicAll
+	fieldOnly := c.Snapshot(Pyro)
+	fieldOnly.Dynamic = DynamicField
+	//ability specific bonuses added after the snapshot should survive a refresh
+	dynamic.Stats[CR] += 0.2
+
+	//buffs change between cast and hit
+	s.Frame = 30
+	delete(c.Mods, "cast")
+	c.Mods["proc"] = map[StatType]float64{ATKP: 0.36}
+	s.AddFieldEffect(FieldEffect{
+		Key:      "field",
+		Duration: 600,
+		Target:   FieldTargetActive,
+		Stats:    map[StatType]float64{ATK: 100},
+		Mod: func(snap *snapshot) {
+			snap.DmgBonus += 0.2
+		},
+	})
+
+	s.refreshSnapshot(&dynamic)
+	if dynamic.Stats[ATKP] != 0.36 || dynamic.Stats[ATK] != 100 {
+		t.Errorf("dynamic hit should use live stats, got %v", dynamic.Stats)
+	}
+	if dynamic.DmgBonus != 0.2 {
+		t.Errorf("dynamic hit should pick up live field modifiers, got dmg bonus %v", dynamic.DmgBonus)
+	}
+	if dynamic.Stats[CR] != 0.2 {
+		t.Errorf("dynamic hit lost ability cr bonus, got %v", dynamic.Stats[CR])
+	}
+
+	s.refreshSnapshot(&snapped)
+	if snapped.Stats[ATKP] != 0.1 || snapped.Stats[ATK] != 0 || snapped.DmgBonus != 0 {
+		t.Errorf("hits should snapshot at cast by default, got %v, dmg bonus %v", snapped.Stats, snapped.DmgBonus)
+	}
+
+	s.refreshSnapshot(&fieldOnly)
+	if fieldOnly.Stats[ATKP] != 0.1 || fieldOnly.Stats[ATK] != 100 || fieldOnly.DmgBonus != 0.2 {
+		t.Errorf("dynamic field hit should use cast mods and live field, got %v, dmg bonus %v", fieldOnly.Stats, fieldOnly.DmgBonus)
+	}
+
+	//a field modifier the hit snapshotted is removed once the field is gone
+	s.Frame = 60
+	s.RemoveFieldEffect("field")
+	s.refreshSnapshot(&dynamic)
+	if dynamic.DmgBonus != 0 || dynamic.Stats[ATK] != 0 {
+		t.Errorf("expected field modifiers to be dropped, got %v, dmg bonus %v", dynamic.Stats, dynamic.DmgBonus)
+	}
+}
+
+func TestSnapshotStatsNotShared(t *testing.T) {
+	s, err := New(testProfile("Test Pyro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.addEffect(func(snap *snapshot) bool {
+		snap.Stats[CR] += 0.4
+		return false
+	}, "test", preDamageHook)
+
+	d := s.Characters[0].Snapshot(Pyro)
+	s.ApplyDamage(d)
+	s.ApplyDamage(d)
+	if d.Stats[CR] != 0 {
+		t.Errorf("pre damage hooks leaked into the snapshot: cr %v", d.Stats[CR])
+	}
+}
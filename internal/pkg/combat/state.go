@@ -0,0 +1,123 @@
+package combat
+
+import "sort"
+
+//State is a read only summary of the sim on the current frame
+type State struct {
+	Frame      int
+	Active     string
+	Cooldown   int    //frames until the next action can start
+	Current    *Event //action still playing out, if any
+	Characters []CharacterState
+	Enemy      EnemyState
+	Shields    []Shield
+	Fields     []FieldState
+	Scheduled  []ScheduledState //pending actions, in the order they run
+}
+
+//CharacterState is one character's part of the state
+type CharacterState struct {
+	Name      string
+	HP        float64
+	MaxHP     float64
+	Energy    float64
+	MaxEnergy float64
+	Cooldowns map[string]int
+	Mods      map[string]map[StatType]float64
+	Infusion  eleType `json:",omitempty"`
+}
+
+//EnemyState is the enemy's part of the state
+type EnemyState struct {
+	Auras    map[eleType]AuraState
+	Statuses map[string]int    //frames left
+	Damage   float64           //taken so far
+	Shield   *EnemyShieldState `json:",omitempty"`
+}
+
+//AuraState is an aura on the enemy
+type AuraState struct {
+	Gauge    float64
+	Unit     string
+	Duration int //frames left
+}
+
+//EnemyShieldState is what's left of the enemy's elemental shield
+type EnemyShieldState struct {
+	Element eleType
+	Gauge   float64
+	HP      float64
+}
+
+//FieldState is an active field effect
+type FieldState struct {
+	Key   string
+	Owner string
+	Left  int //frames left
+}
+
+//ScheduledState is a pending scheduled action
+type ScheduledState struct {
+	Key  string
+	Tick int //times it has run
+}
+
+//State returns a summary of the sim on the current frame
+func (s *Sim) State() State {
+	st := State{
+		Frame:    s.Frame,
+		Active:   s.Characters[s.Active].Profile.Name,
+		Cooldown: s.cooldown,
+		Enemy: EnemyState{
+			Auras:    make(map[eleType]AuraState),
+			Statuses: make(map[string]int),
+			Damage:   s.Target.damage,
+		},
+	}
+	if s.current != nil {
+		e := *s.current
+		st.Current = &e
+	}
+	for _, c := range s.Characters {
+		x := CharacterState{
+			Name:      c.Profile.Name,
+			HP:        c.HP,
+			MaxHP:     c.MaxHP(),
+			Energy:    c.Energy,
+			MaxEnergy: c.MaxEnergy,
+			Cooldowns: make(map[string]int),
+			Mods:      make(map[string]map[StatType]float64),
+			Infusion:  c.Infusion(),
+		}
+		for k, v := range c.Cooldown {
+			x.Cooldowns[k] = v
+		}
+		for k, v := range c.Mods {
+			x.Mods[k] = copyStatMap(v)
+		}
+		st.Characters = append(st.Characters, x)
+	}
+	for k, v := range s.Target.auras {
+		st.Enemy.Auras[k] = AuraState{Gauge: v.gauge, Unit: v.unit, Duration: v.duration}
+	}
+	for k, v := range s.Target.status {
+		st.Enemy.Statuses[k] = v
+	}
+	if sh := s.Target.shield; sh != nil {
+		st.Enemy.Shield = &EnemyShieldState{Element: sh.Element, Gauge: sh.gauge, HP: sh.hp}
+	}
+	for _, v := range s.shields {
+		st.Shields = append(st.Shields, v)
+	}
+	sort.Slice(st.Shields, func(i, j int) bool { return st.Shields[i].Key < st.Shields[j].Key })
+	for _, f := range s.fields {
+		st.Fields = append(st.Fields, FieldState{Key: f.Key, Owner: f.Owner, Left: f.expiry - s.Frame})
+	}
+	sort.Slice(st.Fields, func(i, j int) bool { return st.Fields[i].Key < st.Fields[j].Key })
+	for _, k := range s.actionOrder {
+		if t, ok := s.actions[k]; ok {
+			st.Scheduled = append(st.Scheduled, ScheduledState{Key: k, Tick: t.tick})
+		}
+	}
+	return st
+}
@@ -0,0 +1,172 @@
+package combat
+
+//Uptime is how long something was active over a run
+type Uptime struct {
+	Frames    int
+	Fraction  float64 //of the whole run
+	Intervals []Interval
+}
+
+//Interval is a span of frames; End is exclusive
+type Interval struct {
+	Start int
+	End   int
+}
+
+//Uptimes collects the uptime of every mod, aura, status and field effect seen
+//over a run
+type Uptimes struct {
+	Mods      map[string]map[string]*Uptime //by character, then mod key
+	Cooldowns map[string]map[string]*Uptime //by character, then cooldown key
+	Auras     map[eleType]*Uptime
+	Statuses  map[string]*Uptime
+	Fields    map[string]*Uptime
+}
+
+func newUptimes() Uptimes {
+	return Uptimes{
+		Mods:      make(map[string]map[string]*Uptime),
+		Cooldowns: make(map[string]map[string]*Uptime),
+		Auras:     make(map[eleType]*Uptime),
+		Statuses:  make(map[string]*Uptime),
+		Fields:    make(map[string]*Uptime),
+	}
+}
+
+//clone deep copies the uptimes
+func (u Uptimes) clone() Uptimes {
+	c := Uptimes{
+		Mods:      make(map[string]map[string]*Uptime, len(u.Mods)),
+		Cooldowns: make(map[string]map[string]*Uptime, len(u.Cooldowns)),
+		Auras:     make(map[eleType]*Uptime, len(u.Auras)),
+		Statuses:  cloneUptimes(u.Statuses),
+		Fields:    cloneUptimes(u.Fields),
+	}
+	for k, v := range u.Mods {
+		c.Mods[k] = cloneUptimes(v)
+	}
+	for k, v := range u.Cooldowns {
+		c.Cooldowns[k] = cloneUptimes(v)
+	}
+	for k, v := range u.Auras {
+		c.Auras[k] = v.clone()
+	}
+	return c
+}
+
+func cloneUptimes(m map[string]*Uptime) map[string]*Uptime {
+	c := make(map[string]*Uptime, len(m))
+	for k, v := range m {
+		c[k] = v.clone()
+	}
+	return c
+}
+
+func (u *Uptime) clone() *Uptime {
+	c := *u
+	c.Intervals = append([]Interval(nil), u.Intervals...)
+	return &c
+}
+
+//mark records u as active on frame f, extending the last interval if it was
+//also active on the previous frame
+func (u *Uptime) mark(f int) {
+	u.Frames++
+	if n := len(u.Intervals); n > 0 && u.Intervals[n-1].End == f {
+		u.Intervals[n-1].End = f + 1
+		return
+	}
+	u.Intervals = append(u.Intervals, Interval{Start: f, End: f + 1})
+}
+
+func markUptime(m map[string]*Uptime, key string, f int) {
+	u, ok := m[key]
+	if !ok {
+		u = &Uptime{}
+		m[key] = u
+	}
+	u.mark(f)
+}
+
+//conditionalMod is a buff a damage hook applies while its condition holds
+//(i.e. blizzard strayer against cryo affected enemies), rather than one kept
+//in Character.Mods
+type conditionalMod struct {
+	char   string
+	key    string
+	active func() bool
+}
+
+//addConditionalMod registers a hook based buff of the character so its uptime
+//shows up with the character's mods; active returns whether the hook would
+//currently apply it
+func (s *Sim) addConditionalMod(char, key string, active func() bool) {
+	s.conditionals = append(s.conditionals, conditionalMod{char: char, key: key, active: active})
+}
+
+//trackUptime records everything active on the current frame
+func (s *Sim) trackUptime() {
+	u := &s.stats.Uptime
+	for _, c := range s.Characters {
+		m, ok := u.Mods[c.Profile.Name]
+		if !ok {
+			m = make(map[string]*Uptime)
+			u.Mods[c.Profile.Name] = m
+		}
+		for k := range c.Mods {
+			markUptime(m, k, s.Frame)
+		}
+		for _, x := range s.conditionals {
+			if x.char == c.Profile.Name && x.active() {
+				markUptime(m, x.key, s.Frame)
+			}
+		}
+		cd, ok := u.Cooldowns[c.Profile.Name]
+		if !ok {
+			cd = make(map[string]*Uptime)
+			u.Cooldowns[c.Profile.Name] = cd
+		}
+		for k := range c.Cooldown {
+			markUptime(cd, k, s.Frame)
+		}
+	}
+	for e := range s.Target.auras {
+		a, ok := u.Auras[e]
+		if !ok {
+			a = &Uptime{}
+			u.Auras[e] = a
+		}
+		a.mark(s.Frame)
+	}
+	for k := range s.Target.status {
+		markUptime(u.Statuses, k, s.Frame)
+	}
+	for k := range s.fields {
+		markUptime(u.Fields, k, s.Frame)
+	}
+}
+
+//fractions fills in each uptime as a fraction of the run
+func (u Uptimes) fractions(frames int) {
+	if frames == 0 {
+		return
+	}
+	set := func(x *Uptime) {
+		x.Fraction = float64(x.Frames) / float64(frames)
+	}
+	for _, c := range []map[string]map[string]*Uptime{u.Mods, u.Cooldowns} {
+		for _, m := range c {
+			for _, x := range m {
+				set(x)
+			}
+		}
+	}
+	for _, x := range u.Auras {
+		set(x)
+	}
+	for _, m := range []map[string]*Uptime{u.Statuses, u.Fields} {
+		for _, x := range m {
+			set(x)
+		}
+	}
+}
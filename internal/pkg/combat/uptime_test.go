@@ -0,0 +1,87 @@
+package combat
+
+import "testing"
+
+func TestUptimeMark(t *testing.T) {
+	var u Uptime
+	for _, f := range []int{0, 1, 2, 5, 6} {
+		u.mark(f)
+	}
+	if u.Frames != 5 || len(u.Intervals) != 2 {
+		t.Fatalf("expected 5 frames over 2 intervals, got %+v", u)
+	}
+	if u.Intervals[0] != (Interval{0, 3}) || u.Intervals[1] != (Interval{5, 7}) {
+		t.Errorf("unexpected intervals %v", u.Intervals)
+	}
+}
+
+func TestUptime(t *testing.T) {
+	s, err := New(testProfile("Test Pyro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Characters[0]
+	//a 1s mod, a 2s enemy status, and a 3s field every 10s
+	c.Attack = func(s *Sim, p ActionParams) int {
+		c.Mods["test-mod"] = map[StatType]float64{ATKP: 0.1}
+		s.AddAction(func(s *Sim, tick int) bool {
+			if s.Frame-c.Store["mod-start"].(int) >= 60 {
+				delete(c.Mods, "test-mod")
+				return true
+			}
+			return false
+		}, "test-mod")
+		c.Store["mod-start"] = s.Frame
+		s.Target.AddStatus("test-status", 120)
+		s.AddFieldEffect(FieldEffect{Key: "test-field", Duration: 180, Target: FieldTargetArea})
+		return 600
+	}
+	r := s.Run(20, []Action{{TargetCharIndex: 0, Type: ActionTypeAttack}})
+
+	mod := r.Uptime.Mods["Test Pyro"]["test-mod"]
+	if mod == nil || len(mod.Intervals) != 2 || mod.Fraction < 0.09 || mod.Fraction > 0.11 {
+		t.Errorf("expected ~10%% mod uptime over 2 intervals, got %+v", mod)
+	}
+	status := r.Uptime.Statuses["test-status"]
+	if status == nil || status.Fraction < 0.19 || status.Fraction > 0.21 {
+		t.Errorf("expected ~20%% status uptime, got %+v", status)
+	}
+	field := r.Uptime.Fields["test-field"]
+	if field == nil || field.Fraction < 0.29 || field.Fraction > 0.31 {
+		t.Errorf("expected ~30%% field uptime, got %+v", field)
+	}
+	if len(r.Uptime.Auras) != 0 {
+		t.Errorf("expected no auras, got %v", r.Uptime.Auras)
+	}
+}
+
+func TestConditionalUptime(t *testing.T) {
+	p := testProfile("Test Cryo")
+	p.Characters[0].Artifacts = make(map[Slot]Artifact)
+	for _, slot := range []Slot{Flower, Feather, Sands, Goblet} {
+		p.Characters[0].Artifacts[slot] = Artifact{Set: "Blizzard Strayer"}
+	}
+	s, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Characters[0]
+	//a 1A cryo hit every 20s keeps the enemy cryo affected for 9.5s of every 20
+	c.Attack = func(s *Sim, p ActionParams) int {
+		d := c.Snapshot(Cryo)
+		d.ApplyAura = true
+		d.AuraGauge = 1
+		d.AuraUnit = "A"
+		s.ApplyDamage(d)
+		return 1200
+	}
+	r := s.Run(40, []Action{{TargetCharIndex: 0, Type: ActionTypeAttack}})
+
+	bs := r.Uptime.Mods["Test Cryo"]["Blizzard Strayer 4PC"]
+	if bs == nil || len(bs.Intervals) != 2 || bs.Fraction < 0.47 || bs.Fraction > 0.48 {
+		t.Errorf("expected ~47.5%% blizzard strayer uptime over 2 intervals, got %+v", bs)
+	}
+	if _, ok := r.Uptime.Mods["Test Cryo"]["Cryo Resonance"]; ok {
+		t.Errorf("expected no cryo resonance without a full team")
+	}
+}
@@ -2,17 +2,56 @@ package combat
 
 import (
 	"fmt"
-
-	"go.uber.org/zap"
+	"math/rand"
 )
 
-func print(f int, debug bool, msg string, data ...interface{}) {
-	// fmt.Printf("[%.2fs|%v]: %v\n", float64(f)/60, f, fmt.Sprintf(msg, data...))
+//print logs msg to this sim's logger, stamped with the current frame
+func (s *Sim) print(debug bool, msg string, data ...interface{}) {
+	f := s.Frame
 	if debug {
-		zap.S().Debugf("[%.2fs|%v]: %v", float64(f)/60, f, fmt.Sprintf(msg, data...))
+		s.log.Debugf("[%.2fs|%v]: %v", float64(f)/60, f, fmt.Sprintf(msg, data...))
 		return
 	}
-	zap.S().Infof("[%.2fs|%v]: %v", float64(f)/60, f, fmt.Sprintf(msg, data...))
+	s.log.Infof("[%.2fs|%v]: %v", float64(f)/60, f, fmt.Sprintf(msg, data...))
+}
+
+//source is a splitmix64 random source; unlike the standard library's its state
+//is a single value that checkpoints can copy
+type source struct {
+	state uint64
+}
+
+func (s *source) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+func (s *source) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *source) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+//randFloat draws from r, falling back to the global source if r is nil
+func randFloat(r *rand.Rand) float64 {
+	if r == nil {
+		return rand.Float64()
+	}
+	return r.Float64()
+}
+
+//randNorm draws from a standard normal distribution using r, falling back to
+//the global source if r is nil
+func randNorm(r *rand.Rand) float64 {
+	if r == nil {
+		return rand.NormFloat64()
+	}
+	return r.NormFloat64()
 }
 
 func PrintFrames(f int) string {
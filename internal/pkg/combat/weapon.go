@@ -1,10 +1,6 @@
 package combat
 
-import (
-	"fmt"
-
-	"go.uber.org/zap"
-)
+import "fmt"
 
 func weaponPrototypeCrescent(c *Character, s *Sim, r int) {
 	//add on hit effect to sim?
@@ -19,14 +15,12 @@ func weaponPrototypeCrescent(c *Character, s *Sim, r int) {
 		}
 		//add a new action that adds % dmg to current char and removes itself after
 		//10 seconds
-		tick := 0
-		s.AddAction(func(s *Sim) bool {
+		s.AddAction(func(s *Sim, tick int) bool {
 			if tick >= 10*60 {
 				delete(c.Mods, "Prototype-Crescent-Proc")
-				zap.S().Debugw("prototype crescent buff expired", "tick", tick)
+				s.log.Debugw("prototype crescent buff expired", "tick", tick)
 				return true
 			}
-			tick++
 			if _, ok := c.Mods["Prototype-Crescent-Proc"]; !ok {
 				c.Mods["Prototype-Crescent-Proc"] = make(map[StatType]float64)
 				atkmod := 0.36
@@ -40,7 +34,7 @@ func weaponPrototypeCrescent(c *Character, s *Sim, r int) {
 				case 5:
 					atkmod = 0.72
 				}
-				zap.S().Debugw("applying prototype crescent buff", "%", atkmod, "tick", tick)
+				s.log.Debugw("applying prototype crescent buff", "%", atkmod, "tick", tick)
 				c.Mods["Prototype-Crescent-Proc"][ATKP] = atkmod
 			}
 			return false
@@ -48,3 +42,25 @@ func weaponPrototypeCrescent(c *Character, s *Sim, r int) {
 		return false
 	}, "prototype-crescent-proc", postDamageHook)
 }
+
+func weaponAmos(c *Character, s *Sim, r int) {
+	//normal and charged attack dmg +12%; each 0.1s of arrow flight time adds
+	//another 8%, up to 5 stacks
+	base := 0.09 + 0.03*float64(r)
+	stack := 0.06 + 0.02*float64(r)
+	s.addEffect(func(snap *snapshot) bool {
+		if snap.CharName != c.Profile.Name {
+			return false
+		}
+		if snap.AbilType != ActionTypeAttack && snap.AbilType != ActionTypeChargedAttack {
+			return false
+		}
+		n := snap.TravelFrames / 6
+		if n > 5 {
+			n = 5
+		}
+		s.log.Debugw("applying amos bonus", "travel", snap.TravelFrames, "stacks", n)
+		snap.DmgBonus += base + stack*float64(n)
+		return false
+	}, fmt.Sprintf("amos-%v", c.Profile.Name), preDamageHook)
+}
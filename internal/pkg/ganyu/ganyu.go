@@ -1,12 +1,18 @@
 package ganyu
 
 import (
+	_ "embed"
 	"fmt"
 
 	"github.com/srliao/gansim/internal/pkg/combat"
 	"go.uber.org/zap"
 )
 
+//go:embed frames.yaml
+var frames []byte
+
+var frameTable = combat.MustLoadFrameTable(frames)
+
 func init() {
 	combat.RegisterCharFunc("Ganyu", New)
 }
@@ -18,23 +24,44 @@ func New(s *combat.Sim, log *zap.SugaredLogger) *combat.Character {
 	c.Skill = skill(c, log)
 	c.MaxEnergy = 60
 	c.Energy = 60
+	c.Element = combat.Cryo
+	c.Frames = frameTable
+	c.WeaponClass = combat.WeaponClassBow
 
 	return c
 }
 
+//arrowSpeed is how far a frost flake arrow travels per frame, in meters
+const arrowSpeed = 0.25
+
+//charge fires a fully charged frost flake arrow; param charge_level: 1 fires a
+//level 1 aimed shot instead
 func charge(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
-	return func(s *combat.Sim) int {
-		i := 0
-		initial := func(s *combat.Sim) bool {
-			if i < 20 {
-				i++
+	aimed := aimedShot(c, log)
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		if p.Int("charge_level", 2) == 1 {
+			c.Variant()
+			return aimed(s, p)
+		}
+		//default to a weak point hit from 5m away
+		arrow := c.Accuracy("Frost Flake Arrow", combat.Accuracy{WeakPoint: 1, Distance: 5})
+		bloomAcc := c.Accuracy("Frost Flake Bloom", combat.Accuracy{Distance: arrow.Distance})
+		travel := arrow.TravelFrames(arrowSpeed)
+
+		initial := func(s *combat.Sim, tick int) bool {
+			if tick < travel {
 				return false
 			}
+			if arrow.Missed() {
+				log.Infof("[%v]: Ganyu frost arrow missed", combat.PrintFrames(s.Frame))
+				return true
+			}
 			//abil
 			d := c.Snapshot(combat.Cryo)
 			d.Abil = "Frost Flake Arrow"
 			d.AbilType = combat.ActionTypeChargedAttack
-			d.HitWeakPoint = true
+			d.HitWeakPoint = arrow.HitWeakPoint()
+			d.TravelFrames = travel
 			d.Mult = ffa[c.Profile.TalentLevel[combat.ActionTypeAttack]-1]
 			d.AuraGauge = 1
 			d.AuraUnit = "A"
@@ -54,17 +81,22 @@ func charge(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
 			return true
 		}
 
-		b := 0
-		//apply second bloom w/ more travel time
-		bloom := func(s *combat.Sim) bool {
-			if b < 50 {
-				b++
+		//apply second bloom 30 frames after the arrow lands
+		bloom := func(s *combat.Sim, tick int) bool {
+			if tick < travel+30 {
 				return false
 			}
+			//the bloom is AoE and can miss even if the arrow hit
+			if bloomAcc.Missed() {
+				log.Infof("[%v]: Ganyu frost flake bloom missed", combat.PrintFrames(s.Frame))
+				return true
+			}
 			//abil
 			d := c.Snapshot(combat.Cryo)
 			d.Abil = "Frost Flake Bloom"
 			d.AbilType = combat.ActionTypeChargedAttack
+			d.HitWeakPoint = bloomAcc.HitWeakPoint()
+			d.TravelFrames = travel
 			d.Mult = ffb[c.Profile.TalentLevel[combat.ActionTypeAttack]-1]
 			d.ApplyAura = true
 			d.AuraGauge = 1
@@ -89,9 +121,41 @@ func charge(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
 	}
 }
 
+//aimedShot is a level 1 charged shot; a single cryo arrow with no bloom
+func aimedShot(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		acc := c.Accuracy("Aimed Shot", combat.Accuracy{WeakPoint: 1, Distance: 5})
+		travel := acc.TravelFrames(arrowSpeed)
+
+		s.AddAction(func(s *combat.Sim, tick int) bool {
+			if tick < travel {
+				return false
+			}
+			if acc.Missed() {
+				log.Infof("[%v]: Ganyu aimed shot missed", combat.PrintFrames(s.Frame))
+				return true
+			}
+			d := c.Snapshot(combat.Cryo)
+			d.Abil = "Aimed Shot"
+			d.AbilType = combat.ActionTypeChargedAttack
+			d.HitWeakPoint = acc.HitWeakPoint()
+			d.TravelFrames = travel
+			d.Mult = aimedLvl1[c.Profile.TalentLevel[combat.ActionTypeAttack]-1]
+			d.ApplyAura = true
+			d.AuraGauge = 1
+			d.AuraUnit = "A"
+			damage := s.ApplyDamage(d)
+			log.Infof("[%v]: Ganyu aimed shot dealt %.0f damage", combat.PrintFrames(s.Frame), damage)
+			return true
+		}, fmt.Sprintf("%v-Ganyu-CA-Aimed", s.Frame))
+
+		return 94
+	}
+}
+
 func burst(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
-	return func(s *combat.Sim) int {
-		//snap shot stats at cast time here
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		//celestial shower doesn't snapshot; stats are read live on each tick
 		d := c.Snapshot(combat.Cryo)
 		d.Abil = "Celestial Shower"
 		d.AbilType = combat.ActionTypeBurst
@@ -103,24 +167,28 @@ func burst(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
 		//apply weapon stats here
 		//burst should be instant
 		//should add a hook to the unit, triggering damage every 1 sec
-		//also add a field effect
-		tick := 0
-		storm := func(s *combat.Sim) bool {
+		storm := func(s *combat.Sim, tick int) bool {
 			if tick > 900 {
 				return true
 			}
 			//check if multiples of 60s; also add an initial delay of 120 frames
 			if tick%60 != 0 || tick < 120 {
-				tick++
 				return false
 			}
 			//do damage
 			damage := s.ApplyDamage(d)
 			log.Infof("[%v]: Ganyu burst (tick) dealt %.0f damage", combat.PrintFrames(s.Frame), damage)
-			tick++
 			return false
 		}
 		s.AddAction(storm, fmt.Sprintf("%v-Ganyu-Burst", s.Frame))
+		//A4: celestial shower grants 20% cryo dmg bonus to active party members in the AoE
+		s.AddFieldEffect(combat.FieldEffect{
+			Key:      "Ganyu-A4",
+			Owner:    c.Profile.Name,
+			Duration: 15 * 60,
+			Target:   combat.FieldTargetActive,
+			Stats:    map[combat.StatType]float64{combat.CryoP: 0.2},
+		})
 		//add cooldown to sim
 		c.Cooldown["burst-cd"] = 15 * 60
 
@@ -129,7 +197,7 @@ func burst(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
 }
 
 func skill(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
-	return func(s *combat.Sim) int {
+	return func(s *combat.Sim, p combat.ActionParams) int {
 		//snap shot stats at cast time here
 		d := c.Snapshot(combat.Cryo)
 		d.Abil = "Ice Lotus"
@@ -139,20 +207,18 @@ func skill(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
 		d.AuraGauge = 1
 		d.AuraUnit = "A"
 
-		tick := 0
-		flower := func(s *combat.Sim) bool {
+		flower := func(s *combat.Sim, tick int) bool {
 			if tick < 6*60 {
 				return false
 			}
 			//do damage
 			damage := s.ApplyDamage(d)
-			zap.S().Infof("[%v]: Ganyu ice lotus (tick) dealt %.0f damage", combat.PrintFrames(s.Frame), damage)
-			tick++
-			return false
+			log.Infof("[%v]: Ganyu ice lotus dealt %.0f damage", combat.PrintFrames(s.Frame), damage)
+			return true
 		}
 		s.AddAction(flower, fmt.Sprintf("%v-Ganyu-Skill", s.Frame))
 		//add cooldown to sim
-		c.Cooldown["cd-skill"] = 15 * 60
+		c.Cooldown["skill-cd"] = 15 * 60
 
 		return 30
 	}
@@ -35,6 +35,23 @@ var (
 		2.88,
 		3.04,
 	}
+	aimedLvl1 = []float64{
+		1.24,
+		1.333,
+		1.426,
+		1.55,
+		1.643,
+		1.736,
+		1.86,
+		1.984,
+		2.108,
+		2.232,
+		2.356,
+		2.48,
+		2.635,
+		2.79,
+		2.945,
+	}
 	ffb = []float64{
 		2.176,
 		2.3392,
@@ -0,0 +1,110 @@
+package xiao
+
+var (
+	//N1 and N4 hit twice
+	normalAttack = [][]float64{
+		{0.2754, 0.5694, 0.6855, 0.3766, 0.7154, 0.9583}, //lvl 1
+		{0.2978, 0.6157, 0.7413, 0.4073, 0.7736, 1.0363},
+		{0.3202, 0.6621, 0.7971, 0.4379, 0.8319, 1.1143},
+		{0.3523, 0.7283, 0.8768, 0.4817, 0.9151, 1.2258},
+		{0.3747, 0.7747, 0.9326, 0.5124, 0.9733, 1.3038},
+		{0.4003, 0.8276, 0.9964, 0.5474, 1.0398, 1.3929},
+		{0.4355, 0.9004, 1.084, 0.5956, 1.1313, 1.5155},
+		{0.4707, 0.9733, 1.1717, 0.6437, 1.2228, 1.638},
+		{0.506, 1.0461, 1.2594, 0.6919, 1.3143, 1.7606},
+		{0.5444, 1.1255, 1.355, 0.7444, 1.4141, 1.8943},
+		{0.5884, 1.2166, 1.4646, 0.8046, 1.5285, 2.0475},
+		{0.6402, 1.3236, 1.5934, 0.8754, 1.6629, 2.2276},
+		{0.6919, 1.4306, 1.7223, 0.9462, 1.7974, 2.4076},
+		{0.7437, 1.5376, 1.8511, 1.017, 1.9319, 2.5878},
+		{0.8002, 1.6544, 1.9917, 1.0942, 2.0786, 2.7843}, //lvl 15
+	}
+	chargeAttack = []float64{
+		1.2109, //lvl 1
+		1.3095,
+		1.408,
+		1.5489,
+		1.6474,
+		1.76,
+		1.9149,
+		2.0698,
+		2.2247,
+		2.3936,
+		2.5872,
+		2.8147,
+		3.0423,
+		3.2699,
+		3.5183, //lvl 15
+	}
+	//plunge; collision, low plunge, high plunge
+	plungeHits = [][]float64{
+		{0.8183, 1.6363, 2.0439}, //lvl 1
+		{0.8849, 1.7695, 2.2103},
+		{0.9515, 1.9027, 2.3766},
+		{1.0467, 2.093, 2.6144},
+		{1.1133, 2.2262, 2.7807},
+		{1.1894, 2.3784, 2.9708},
+		{1.2941, 2.5876, 3.2322},
+		{1.3987, 2.7969, 3.4936},
+		{1.5034, 3.0062, 3.7551},
+		{1.6175, 3.2345, 4.0402},
+		{1.7484, 3.4961, 4.367},
+		{1.9021, 3.8036, 4.751},
+		{2.0559, 4.111, 5.1351},
+		{2.2097, 4.4187, 5.5193},
+		{2.3776, 4.7543, 5.9386}, //lvl 15
+	}
+	lemniscatic = []float64{
+		2.528, //lvl 1
+		2.7176,
+		2.9072,
+		3.16,
+		3.3496,
+		3.5392,
+		3.792,
+		4.0448,
+		4.2976,
+		4.5504,
+		4.8032,
+		5.056,
+		5.372,
+		5.688,
+		6.004, //lvl 15
+	}
+	//normal, charged, and plunge dmg bonus during bane of all evil
+	baneBonus = []float64{
+		0.5845, //lvl 1
+		0.6195,
+		0.6545,
+		0.7,
+		0.735,
+		0.77,
+		0.8155,
+		0.861,
+		0.9065,
+		0.952,
+		0.9975,
+		1.043,
+		1.0885,
+		1.134,
+		1.1795, //lvl 15
+	}
+	//hp drain per second during bane of all evil
+	baneDrain = []float64{
+		0.025, //lvl 1
+		0.025,
+		0.03,
+		0.03,
+		0.035,
+		0.035,
+		0.04,
+		0.04,
+		0.045,
+		0.045,
+		0.05,
+		0.05,
+		0.055,
+		0.055,
+		0.06, //lvl 15
+	}
+)
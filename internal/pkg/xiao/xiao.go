@@ -0,0 +1,234 @@
+package xiao
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/srliao/gansim/internal/pkg/combat"
+	"go.uber.org/zap"
+)
+
+//go:embed frames.yaml
+var frames []byte
+
+var frameTable = combat.MustLoadFrameTable(frames)
+
+func init() {
+	combat.RegisterCharFunc("Xiao", New)
+}
+
+func New(s *combat.Sim, log *zap.SugaredLogger) *combat.Character {
+	c := &combat.Character{}
+	c.Attack = attack(c, log)
+	c.ChargeAttack = charge(c, log)
+	c.PlungeAttack = plunge(c, log)
+	c.Skill = skill(c, log)
+	c.Burst = burst(c, log)
+	c.MaxEnergy = 70
+	c.Energy = 70
+	c.Element = combat.Anemo
+	c.Frames = frameTable
+	c.WeaponClass = combat.WeaponClassPolearm
+
+	return c
+}
+
+//delayed returns an action that applies the damage after the given number of frames
+func delayed(f func(s *combat.Sim) bool, delay int) combat.ActionFunc {
+	return func(s *combat.Sim, tick int) bool {
+		if tick < delay {
+			return false
+		}
+		f(s)
+		return true
+	}
+}
+
+//hit applies one hit of a normal, charged, or plunge attack after delay frames;
+//these get bane of all evil's dmg bonus if it's active when the hit lands, and
+//its anemo infusion through the snapshot
+func hit(c *combat.Character, s *combat.Sim, log *zap.SugaredLogger, abil string, t combat.ActionType, mult float64, delay int) {
+	c.AddHit(func(s *combat.Sim) {
+		d := c.Snapshot(combat.Physical)
+		d.Abil = abil
+		d.AbilType = t
+		d.Mult = mult
+		if _, ok := c.Cooldown["burst-active"]; ok {
+			d.DmgBonus += baneBonus[c.Profile.TalentLevel[combat.ActionTypeBurst]-1]
+		}
+		damage := s.ApplyDamage(d)
+		log.Infof("[%v]: Xiao %v dealt %.0f damage", combat.PrintFrames(s.Frame), abil, damage)
+	}, delay, fmt.Sprintf("%v-Xiao-%v-%v", s.Frame, abil, delay))
+}
+
+//frames for each hit of the normal attack chain; [delay before each hit..., animation]
+var attackFrames = [][]int{
+	{12, 20, 28},
+	{14, 27},
+	{22, 40},
+	{20, 30, 43},
+	{18, 35},
+	{32, 60},
+}
+
+func attack(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		//reset the chain if the last attack was too long ago
+		n, ok := c.Store["attack-counter"].(int)
+		if _, chain := c.Cooldown["attack-chain"]; !chain || !ok || n >= len(attackFrames) {
+			n = 0
+		}
+		mult := normalAttack[c.Profile.TalentLevel[combat.ActionTypeAttack]-1][n]
+		f := attackFrames[n]
+		for _, delay := range f[:len(f)-1] {
+			hit(c, s, log, fmt.Sprintf("Normal %v", n+1), combat.ActionTypeAttack, mult, delay)
+		}
+
+		c.Store["attack-counter"] = n + 1
+		c.Cooldown["attack-chain"] = f[len(f)-1] + 30
+
+		return f[len(f)-1]
+	}
+}
+
+func charge(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		hit(c, s, log, "Charge", combat.ActionTypeChargedAttack, chargeAttack[c.Profile.TalentLevel[combat.ActionTypeAttack]-1], 16)
+		delete(c.Cooldown, "attack-chain")
+		return 50
+	}
+}
+
+//plunge does a high plunge while bane of all evil is active (xiao jumps much
+//higher during his burst), otherwise a low plunge; param high overrides this
+func plunge(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	low := plungeAttack(c, log, false)
+	high := plungeAttack(c, log, true)
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		_, h := c.Cooldown["burst-active"]
+		if p.Has("high") {
+			h = p.Bool("high")
+		}
+		if h {
+			c.Variant()
+			return high(s, p)
+		}
+		return low(s, p)
+	}
+}
+
+func plungeAttack(c *combat.Character, log *zap.SugaredLogger, high bool) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		mult := plungeHits[c.Profile.TalentLevel[combat.ActionTypeAttack]-1]
+		delete(c.Cooldown, "attack-chain")
+		if high {
+			hit(c, s, log, "Plunge Collision", combat.ActionTypePlungeAttack, mult[0], 30)
+			hit(c, s, log, "High Plunge", combat.ActionTypePlungeAttack, mult[2], 46)
+			return 66
+		}
+		hit(c, s, log, "Plunge Collision", combat.ActionTypePlungeAttack, mult[0], 25)
+		hit(c, s, log, "Low Plunge", combat.ActionTypePlungeAttack, mult[1], 40)
+		return 59
+	}
+}
+
+func skill(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		charges, ok := c.Store["skill-charges"].(int)
+		if !ok {
+			charges = 2
+		}
+		if charges == 0 {
+			log.Infof("[%v]: Xiao lemniscatic wind cycling has no charges left", combat.PrintFrames(s.Frame))
+			return 0
+		}
+
+		//A4: each use increases subsequent skill dmg by 15% for 7s, max 3 stacks
+		stacks, _ := c.Store["a4-stacks"].(int)
+		if _, ok := c.Cooldown["a4"]; !ok {
+			stacks = 0
+		}
+
+		d := c.Snapshot(combat.Anemo)
+		d.Abil = "Lemniscatic Wind Cycling"
+		d.AbilType = combat.ActionTypeSkill
+		d.Mult = lemniscatic[c.Profile.TalentLevel[combat.ActionTypeSkill]-1]
+		d.DmgBonus += 0.15 * float64(stacks)
+		d.ApplyAura = true
+		d.AuraGauge = 2
+		d.AuraUnit = "B"
+		s.AddAction(delayed(func(s *combat.Sim) bool {
+			damage := s.ApplyDamage(d)
+			log.Infof("[%v]: Xiao lemniscatic wind cycling dealt %.0f damage", combat.PrintFrames(s.Frame), damage)
+			return true
+		}, 10), fmt.Sprintf("%v-Xiao-Skill", s.Frame))
+
+		if stacks < 3 {
+			stacks++
+		}
+		c.Store["a4-stacks"] = stacks
+		c.Cooldown["a4"] = 7 * 60
+
+		//each charge recovers independently after 10s
+		c.Store["skill-charges"] = charges - 1
+		if charges == 1 {
+			c.Cooldown["skill-cd"] = 10 * 60
+		}
+		s.AddAction(func(s *combat.Sim, tick int) bool {
+			if tick < 10*60 {
+				return false
+			}
+			n, _ := c.Store["skill-charges"].(int)
+			c.Store["skill-charges"] = n + 1
+			delete(c.Cooldown, "skill-cd")
+			return true
+		}, fmt.Sprintf("%v-Xiao-Skill-Charge", s.Frame))
+
+		return 36
+	}
+}
+
+func burst(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		lvl := c.Profile.TalentLevel[combat.ActionTypeBurst] - 1
+		dur := 15 * 60
+
+		c.Cooldown["burst-active"] = dur
+		//the infusion can't be overridden by other infusions
+		c.AddInfusion(combat.Infusion{
+			Key:      "Xiao-Burst",
+			Element:  combat.Anemo,
+			Source:   combat.InfusionSelf,
+			Duration: dur,
+		})
+
+		s.AddAction(func(s *combat.Sim, tick int) bool {
+			if tick > dur {
+				delete(c.Mods, "Xiao-A1")
+				log.Debugf("[%v]: Xiao bane of all evil expired", combat.PrintFrames(s.Frame))
+				return true
+			}
+			//A1: all dmg +5%, increasing by another 5% every 3s up to 25%
+			if tick%180 == 0 {
+				stacks := tick/180 + 1
+				if stacks > 5 {
+					stacks = 5
+				}
+				c.Mods["Xiao-A1"] = map[combat.StatType]float64{combat.DmgP: 0.05 * float64(stacks)}
+			}
+			//hp drain every second; can't drop xiao below 1 hp
+			if tick%60 == 0 && tick > 0 {
+				c.HP -= baneDrain[lvl] * c.HP
+				if c.HP < 1 {
+					c.HP = 1
+				}
+				log.Debugf("[%v]: Xiao hp drained to %.0f", combat.PrintFrames(s.Frame), c.HP)
+			}
+			return false
+		}, fmt.Sprintf("%v-Xiao-Burst", s.Frame))
+
+		c.Energy = 0
+		c.Cooldown["burst-cd"] = 18 * 60
+		return 82
+	}
+}
@@ -0,0 +1,159 @@
+package zhongli
+
+var (
+	normalAttack = [][]float64{
+		{0.3077, 0.3115, 0.3858, 0.4294, 0.1075, 0.5461}, //lvl 1
+		{0.3327, 0.3369, 0.4172, 0.4644, 0.1163, 0.5906},
+		{0.3578, 0.3622, 0.4486, 0.4993, 0.125, 0.635},
+		{0.3936, 0.3984, 0.4935, 0.5492, 0.1375, 0.6985},
+		{0.4186, 0.4238, 0.5249, 0.5842, 0.1463, 0.743},
+		{0.4472, 0.4528, 0.5608, 0.6241, 0.1563, 0.7938},
+		{0.4866, 0.4926, 0.6101, 0.6791, 0.17, 0.8636},
+		{0.526, 0.5324, 0.6594, 0.734, 0.1837, 0.9334},
+		{0.5653, 0.5723, 0.7088, 0.7889, 0.1975, 1.0033},
+		{0.6082, 0.6157, 0.7626, 0.8488, 0.2125, 1.0795},
+		{0.6574, 0.6656, 0.8243, 0.9175, 0.2297, 1.1668},
+		{0.7152, 0.7241, 0.8968, 0.9981, 0.2499, 1.2694},
+		{0.7731, 0.7826, 0.9693, 1.0788, 0.2701, 1.372},
+		{0.8309, 0.8412, 1.0418, 1.1596, 0.2903, 1.4747},
+		{0.894, 0.9051, 1.1209, 1.2476, 0.3123, 1.5867}, //lvl 15
+	}
+	chargeAttack = []float64{
+		1.1067, //lvl 1
+		1.1968,
+		1.2869,
+		1.4156,
+		1.5057,
+		1.6086,
+		1.7501,
+		1.8917,
+		2.0332,
+		2.1876,
+		2.3646,
+		2.5725,
+		2.7805,
+		2.9885,
+		3.2155, //lvl 15
+	}
+	stele = []float64{
+		0.16, //lvl 1
+		0.172,
+		0.184,
+		0.2,
+		0.212,
+		0.224,
+		0.24,
+		0.256,
+		0.272,
+		0.288,
+		0.304,
+		0.32,
+		0.34,
+		0.36,
+		0.38, //lvl 15
+	}
+	resonance = []float64{
+		0.32, //lvl 1
+		0.344,
+		0.368,
+		0.4,
+		0.424,
+		0.448,
+		0.48,
+		0.512,
+		0.544,
+		0.576,
+		0.608,
+		0.64,
+		0.68,
+		0.72,
+		0.76, //lvl 15
+	}
+	hold = []float64{
+		0.8, //lvl 1
+		0.86,
+		0.92,
+		1.0,
+		1.06,
+		1.12,
+		1.2,
+		1.28,
+		1.36,
+		1.44,
+		1.52,
+		1.6,
+		1.7,
+		1.8,
+		1.9, //lvl 15
+	}
+	//shield absorption; % of max hp + flat amount
+	shieldPer = []float64{
+		0.128, //lvl 1
+		0.1376,
+		0.1472,
+		0.16,
+		0.1696,
+		0.1792,
+		0.192,
+		0.2048,
+		0.2176,
+		0.2304,
+		0.2432,
+		0.256,
+		0.272,
+		0.288,
+		0.304, //lvl 15
+	}
+	shieldFlat = []float64{
+		1232, //lvl 1
+		1356,
+		1489,
+		1633,
+		1787,
+		1951,
+		2125,
+		2310,
+		2504,
+		2709,
+		2924,
+		3149,
+		3385,
+		3630,
+		3885, //lvl 15
+	}
+	planet = []float64{
+		4.0108, //lvl 1
+		4.4444,
+		4.878,
+		5.42,
+		5.8536,
+		6.2872,
+		6.8292,
+		7.3712,
+		7.9132,
+		8.4552,
+		8.9972,
+		9.5392,
+		10.1504,
+		10.7616,
+		11.3728, //lvl 15
+	}
+	//petrification duration in seconds
+	petrify = []float64{
+		3.1, //lvl 1
+		3.2,
+		3.3,
+		3.4,
+		3.5,
+		3.6,
+		3.7,
+		3.8,
+		3.9,
+		4,
+		4,
+		4,
+		4,
+		4,
+		4, //lvl 15
+	}
+)
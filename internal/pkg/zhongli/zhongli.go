@@ -0,0 +1,239 @@
+package zhongli
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/srliao/gansim/internal/pkg/combat"
+	"go.uber.org/zap"
+)
+
+//go:embed frames.yaml
+var frames []byte
+
+var frameTable = combat.MustLoadFrameTable(frames)
+
+func init() {
+	combat.RegisterCharFunc("Zhongli", New)
+}
+
+func New(s *combat.Sim, log *zap.SugaredLogger) *combat.Character {
+	c := &combat.Character{}
+	c.Attack = attack(c, log)
+	c.ChargeAttack = charge(c, log)
+	c.Skill = skill(c, log)
+	c.Burst = burst(c, log)
+	c.MaxEnergy = 40
+	c.Energy = 40
+	c.Element = combat.Geo
+	c.Frames = frameTable
+	c.WeaponClass = combat.WeaponClassPolearm
+
+	return c
+}
+
+//delayed returns an action that applies the damage after the given number of frames
+func delayed(f func(s *combat.Sim) bool, delay int) combat.ActionFunc {
+	return func(s *combat.Sim, tick int) bool {
+		if tick < delay {
+			return false
+		}
+		f(s)
+		return true
+	}
+}
+
+//frames for each hit of the normal attack chain; [delay before hit, animation]
+var attackFrames = [][]int{
+	{12, 30},
+	{11, 29},
+	{17, 39},
+	{21, 47},
+	{9, 59}, //4 hits, 9 frames apart
+	{24, 56},
+}
+
+func attack(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		//reset the chain if the last attack was too long ago
+		n, ok := c.Store["attack-counter"].(int)
+		if _, chain := c.Cooldown["attack-chain"]; !chain || !ok || n >= len(attackFrames) {
+			n = 0
+		}
+		mult := normalAttack[c.Profile.TalentLevel[combat.ActionTypeAttack]-1][n]
+		hits := 1
+		if n == 4 {
+			hits = 4
+		}
+		for i := 0; i < hits; i++ {
+			hit := n + 1
+			c.AddHit(func(s *combat.Sim) {
+				d := c.Snapshot(combat.Physical)
+				d.Abil = "Normal"
+				d.AbilType = combat.ActionTypeAttack
+				d.Mult = mult
+				//A4: normal attacks deal additional damage equal to 1.39% of max hp
+				d.FlatDmg = 0.0139 * d.MaxHP()
+				damage := s.ApplyDamage(d)
+				log.Infof("[%v]: Zhongli normal %v dealt %.0f damage", combat.PrintFrames(s.Frame), hit, damage)
+			}, attackFrames[n][0]*(i+1), fmt.Sprintf("%v-Zhongli-Normal-%v-%v", s.Frame, n, i))
+		}
+
+		c.Store["attack-counter"] = n + 1
+		c.Cooldown["attack-chain"] = attackFrames[n][1] + 30
+
+		return attackFrames[n][1]
+	}
+}
+
+func charge(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		c.AddHit(func(s *combat.Sim) {
+			d := c.Snapshot(combat.Physical)
+			d.Abil = "Charge"
+			d.AbilType = combat.ActionTypeChargedAttack
+			d.Mult = chargeAttack[c.Profile.TalentLevel[combat.ActionTypeAttack]-1]
+			//A4: charged attacks deal additional damage equal to 1.39% of max hp
+			d.FlatDmg = 0.0139 * d.MaxHP()
+			damage := s.ApplyDamage(d)
+			log.Infof("[%v]: Zhongli charge attack dealt %.0f damage", combat.PrintFrames(s.Frame), damage)
+		}, 25, fmt.Sprintf("%v-Zhongli-CA", s.Frame))
+		//charge ends the normal attack chain
+		delete(c.Cooldown, "attack-chain")
+
+		return 50
+	}
+}
+
+//skill holds Dominus Lapidis if the Jade Shield is down, otherwise taps it to
+//put down a fresh stele; param hold overrides this
+func skill(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	press := skillPress(c, log)
+	hold := skillHold(c, log)
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		h := !s.HasShield("Jade Shield")
+		if p.Has("hold") {
+			h = p.Bool("hold")
+		}
+		if h {
+			c.Variant()
+			return hold(s, p)
+		}
+		return press(s, p)
+	}
+}
+
+func skillPress(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		lvl := c.Profile.TalentLevel[combat.ActionTypeSkill] - 1
+
+		d := c.Snapshot(combat.Geo)
+		d.Abil = "Stone Stele"
+		d.AbilType = combat.ActionTypeSkill
+		d.Mult = stele[lvl]
+		d.ApplyAura = true
+		d.AuraGauge = 1
+		d.AuraUnit = "A"
+		//A4: stele damage increased by 1.9% of max hp
+		d.FlatDmg = 0.019 * d.MaxHP()
+		damage := s.ApplyDamage(d)
+		log.Infof("[%v]: Zhongli stone stele dealt %.0f damage", combat.PrintFrames(s.Frame), damage)
+
+		placeStele(c, log, s, lvl)
+
+		c.Cooldown["skill-cd"] = 4 * 60
+		return 30
+	}
+}
+
+//placeStele puts down a stone stele that resonates every 2s for 30s. only one
+//stele can exist at a time; older steles stop resonating
+func placeStele(c *combat.Character, log *zap.SugaredLogger, s *combat.Sim, lvl int64) {
+	id := s.Frame
+	c.Store["stele"] = id
+	resonate := func(s *combat.Sim, tick int) bool {
+		if c.Store["stele"] != id || tick > 30*60 {
+			return true
+		}
+		//resonate every 2s
+		if (tick+1)%120 != 0 {
+			return false
+		}
+		d := c.Snapshot(combat.Geo)
+		d.Abil = "Stone Stele Resonance"
+		d.AbilType = combat.ActionTypeSkill
+		d.Mult = resonance[lvl]
+		d.ApplyAura = true
+		d.AuraGauge = 1
+		d.AuraUnit = "A"
+		d.FlatDmg = 0.019 * d.MaxHP()
+		damage := s.ApplyDamage(d)
+		log.Infof("[%v]: Zhongli stele resonance (tick) dealt %.0f damage", combat.PrintFrames(s.Frame), damage)
+		return false
+	}
+	s.AddAction(resonate, fmt.Sprintf("%v-Zhongli-Stele", s.Frame))
+}
+
+func skillHold(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		lvl := c.Profile.TalentLevel[combat.ActionTypeSkill] - 1
+
+		d := c.Snapshot(combat.Geo)
+		d.Abil = "Dominus Lapidis (Hold)"
+		d.AbilType = combat.ActionTypeSkill
+		d.Mult = hold[lvl]
+		d.ApplyAura = true
+		d.AuraGauge = 2
+		d.AuraUnit = "B"
+		d.FlatDmg = 0.019 * d.MaxHP()
+		damage := s.ApplyDamage(d)
+		log.Infof("[%v]: Zhongli hold skill dealt %.0f damage", combat.PrintFrames(s.Frame), damage)
+		//holding puts down a stele as well
+		placeStele(c, log, s, lvl)
+
+		s.AddShield(combat.Shield{
+			Key:      "Jade Shield",
+			Element:  combat.Geo,
+			HP:       shieldPer[lvl]*d.MaxHP() + shieldFlat[lvl],
+			Duration: 20 * 60,
+		})
+		//jade shield decreases elemental and physical res of nearby enemies by 20%
+		s.AddAction(func(s *combat.Sim, tick int) bool {
+			if !s.HasShield("Jade Shield") {
+				delete(s.Target.ResMod, "Jade Shield")
+				return true
+			}
+			s.Target.ResMod["Jade Shield"] = -0.2
+			return false
+		}, "Zhongli-Jade-Shield")
+
+		c.Cooldown["skill-cd"] = 12 * 60
+		return 80
+	}
+}
+
+func burst(c *combat.Character, log *zap.SugaredLogger) combat.AbilFunc {
+	return func(s *combat.Sim, p combat.ActionParams) int {
+		lvl := c.Profile.TalentLevel[combat.ActionTypeBurst] - 1
+
+		s.AddAction(delayed(func(s *combat.Sim) bool {
+			d := c.Snapshot(combat.Geo)
+			d.Abil = "Planet Befall"
+			d.AbilType = combat.ActionTypeBurst
+			d.Mult = planet[lvl]
+			d.ApplyAura = true
+			d.AuraGauge = 4
+			d.AuraUnit = "C"
+			//A4: planet befall damage increased by 33% of max hp
+			d.FlatDmg = 0.33 * d.MaxHP()
+			damage := s.ApplyDamage(d)
+			log.Infof("[%v]: Zhongli planet befall dealt %.0f damage", combat.PrintFrames(s.Frame), damage)
+			s.Target.AddStatus("petrified", int(petrify[lvl]*60))
+			return true
+		}, 100), fmt.Sprintf("%v-Zhongli-Burst", s.Frame))
+
+		c.Energy = 0
+		c.Cooldown["burst-cd"] = 12 * 60
+		return 140
+	}
+}